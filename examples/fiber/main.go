@@ -3,10 +3,10 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/Waryway/Wayframe/internal/env"
-	"github.com/Waryway/Wayframe/internal/web"
-	fiberserver "github.com/Waryway/Wayframe/internal/web/fiber"
+	_ "github.com/Waryway/Wayframe/internal/web/fiber" // registers the "fiber" backend
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -26,17 +26,11 @@ func main() {
 	log.Info("Starting Wayframe Fiber example")
 	log.WithField("port", cfg.Port).Info("Configuration loaded")
 
-	// Create server using web interface
-	srv := fiberserver.New(web.Config{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		IdleTimeout:  cfg.IdleTimeout,
-	})
-
-	// Add middleware
-	srv.Use(fiberserver.LoggingMiddleware(log))
-	srv.Use(fiberserver.RecoveryMiddleware(log))
+	// Create server with logging and recovery middleware from the standard config
+	srv, err := e.NewServer("fiber")
+	if err != nil {
+		panic(fmt.Sprintf("failed to create server: %v", err))
+	}
 
 	// Register routes
 	srv.HandleFunc("/", func(c *fiber.Ctx) error {
@@ -55,8 +49,7 @@ func main() {
 	})
 
 	// Start server
-	log.Infof("Server listening on %s", srv.Addr())
-	if err := srv.Start(cfg.ShutdownTimeout); err != nil {
-		log.Errorf("Server error: %v", err)
+	if err := e.Run(srv); err != nil {
+		os.Exit(1)
 	}
 }
@@ -4,10 +4,10 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/Waryway/Wayframe/internal/env"
-	"github.com/Waryway/Wayframe/internal/web"
-	gorillaserver "github.com/Waryway/Wayframe/internal/web/gorilla"
+	_ "github.com/Waryway/Wayframe/internal/web/gorilla" // registers the "gorilla" backend
 )
 
 func main() {
@@ -26,17 +26,11 @@ func main() {
 	log.Info("Starting Wayframe Gorilla Mux example")
 	log.WithField("port", cfg.Port).Info("Configuration loaded")
 
-	// Create server using web interface
-	srv := gorillaserver.New(web.Config{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		IdleTimeout:  cfg.IdleTimeout,
-	})
-
-	// Add middleware
-	srv.Use(gorillaserver.LoggingMiddleware(log))
-	srv.Use(gorillaserver.RecoveryMiddleware(log))
+	// Create server with logging and recovery middleware from the standard config
+	srv, err := e.NewServer("gorilla")
+	if err != nil {
+		panic(fmt.Sprintf("failed to create server: %v", err))
+	}
 
 	// Register routes
 	srv.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -60,8 +54,7 @@ func main() {
 	})
 
 	// Start server
-	log.Infof("Server listening on %s", srv.Addr())
-	if err := srv.Start(cfg.ShutdownTimeout); err != nil {
-		log.Errorf("Server error: %v", err)
+	if err := e.Run(srv); err != nil {
+		os.Exit(1)
 	}
 }
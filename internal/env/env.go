@@ -3,12 +3,25 @@
 package env
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/Waryway/Wayframe/internal/web"
+	_ "github.com/Waryway/Wayframe/internal/web/stdlib" // always register the stdlib backend
 	"github.com/Waryway/Wayframe/pkg/config"
 	"github.com/Waryway/Wayframe/pkg/logger"
+	"github.com/Waryway/Wayframe/pkg/server"
 )
 
 // Config represents the standard application configuration structure.
@@ -22,12 +35,15 @@ type Config struct {
 	WriteTimeout    time.Duration `config:"write_timeout" default:"10s"`
 	IdleTimeout     time.Duration `config:"idle_timeout" default:"120s"`
 	ShutdownTimeout time.Duration `config:"shutdown_timeout" default:"30s"`
+	WebBackend      string        `config:"web_backend" default:"stdlib"`
 	
 	// Logging configuration
-	LogLevel string `config:"log_level" default:"INFO"`
-	LogFile  string `config:"log_file" default:""`
+	LogLevel     string `config:"log_level" default:"INFO"`
+	LogFile      string `config:"log_file" default:""`
+	LogErrorFile string `config:"log_error_file" default:""` // WARN and ERROR entries, if set
 	
 	// Application configuration
+	AppName     string `config:"app_name" default:""`
 	Environment string `config:"environment" default:"development"`
 	Debug       bool   `config:"debug" default:"false"`
 	
@@ -36,11 +52,17 @@ type Config struct {
 }
 
 // Env represents the application environment with initialized config and logger.
+// Reload replaces AppConfig; code that may run concurrently with a reload
+// should read it through GetAppConfig.
 type Env struct {
 	config       *config.Loader
 	Logger       *logger.Logger
 	AppConfig    *Config
 	customConfig interface{}
+	buildInfo    server.VersionInfo
+
+	mu        sync.Mutex // guards AppConfig after Load, config while loading, and reloaders
+	reloaders []func()
 }
 
 // New creates a new environment with the given prefix for environment variables.
@@ -55,6 +77,8 @@ func New(prefix string) *Env {
 // LoadConfig loads configuration into the provided struct.
 // Uses struct tags for configuration: config, env, default, file
 func (e *Env) LoadConfig(configStruct interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.customConfig = configStruct
 	return e.config.Load(configStruct)
 }
@@ -63,13 +87,10 @@ func (e *Env) LoadConfig(configStruct interface{}) error {
 // This should be called to populate the AppConfig field with values from
 // environment variables, config files, and defaults.
 func (e *Env) LoadStandardConfig() error {
-	// If a config file is specified via env var, load it first
-	if configFile := e.config.String("CONFIG_FILE", ""); configFile != "" {
-		e.config.LoadFile(configFile)
-	}
-	
-	// Load the standard config structure
-	if err := e.config.Load(e.AppConfig); err != nil {
+	e.mu.Lock()
+	err := e.loadStandardConfig()
+	e.mu.Unlock()
+	if err != nil {
 		return err
 	}
 	
@@ -79,19 +100,88 @@ func (e *Env) LoadStandardConfig() error {
 	return nil
 }
 
+// loadStandardConfig loads the standard configuration into AppConfig.
+// The caller must hold e.mu.
+func (e *Env) loadStandardConfig() error {
+	// If a config file is specified via env var, load it first
+	if configFile := e.config.String("CONFIG_FILE", ""); configFile != "" {
+		if err := e.config.LoadFile(configFile); err != nil {
+			return fmt.Errorf("failed to load config file: %w", err)
+		}
+	}
+	return e.config.Load(e.AppConfig)
+}
+
+// LogEffectiveConfig logs a single Info line describing the effective standard
+// configuration, and the custom configuration if one was loaded. Each setting is
+// logged with its resolved value and the source it came from (env, file, or default);
+// values of fields tagged secret:"true" are redacted.
+func (e *Env) LogEffectiveConfig() {
+	attrs := e.describeConfig(e.GetAppConfig())
+	if e.customConfig != nil {
+		attrs = append(attrs, e.describeConfig(e.customConfig)...)
+	}
+	e.Logger.WithField("config", slog.GroupValue(attrs...)).Info("Effective configuration")
+}
+
+// EnvVarNames returns the fully prefixed names of the environment variables
+// read by the standard configuration and the custom configuration, if one was
+// loaded, in field order without duplicates. Fields of nested structs are
+// listed under the names Load reads, such as APP_DB_HOST. It can be used to generate a
+// .env.example file for a service:
+//
+//	for _, name := range e.EnvVarNames() {
+//	    fmt.Printf("%s=\n", name)
+//	}
+func (e *Env) EnvVarNames() []string {
+	docs := e.config.DescribeStruct(e.GetAppConfig())
+	if e.customConfig != nil {
+		docs = append(docs, e.config.DescribeStruct(e.customConfig)...)
+	}
+
+	names := make([]string, 0, len(docs))
+	seen := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		if !seen[doc.EnvVar] {
+			seen[doc.EnvVar] = true
+			names = append(names, doc.EnvVar)
+		}
+	}
+	return names
+}
+
+// describeConfig returns a value/source group for each field of configStruct.
+func (e *Env) describeConfig(configStruct interface{}) []slog.Attr {
+	v := reflect.Indirect(reflect.ValueOf(configStruct))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	docs := e.config.DescribeStruct(configStruct)
+	attrs := make([]slog.Attr, 0, len(docs))
+	for _, doc := range docs {
+		field := v
+		for _, name := range strings.Split(doc.Name, ".") {
+			field = field.FieldByName(name)
+		}
+		value := fmt.Sprint(field.Interface())
+		if doc.Secret && value != "" {
+			value = logger.RedactedValue
+		}
+		source := e.config.Source(doc.Key)
+		if source == "" {
+			source = "unset"
+		}
+		attrs = append(attrs, slog.Group(doc.Key, "value", value, "source", source))
+	}
+	return attrs
+}
+
 // InitLoggerFromConfig initializes the logger based on the AppConfig settings.
 func (e *Env) InitLoggerFromConfig() {
-	level := logger.InfoLevel
-	switch e.AppConfig.LogLevel {
-	case "DEBUG":
-		level = logger.DebugLevel
-	case "INFO":
-		level = logger.InfoLevel
-	case "WARN":
-		level = logger.WarnLevel
-	case "ERROR":
-		level = logger.ErrorLevel
-	}
+	level, levelErr := logger.ParseLevel(e.AppConfig.LogLevel)
 	
 	e.Logger = logger.New(level)
 	
@@ -101,6 +191,22 @@ func (e *Env) InitLoggerFromConfig() {
 			e.Logger.SetOutput(f)
 		}
 	}
+	
+	// Route warnings and errors to a separate file if specified
+	if e.AppConfig.LogErrorFile != "" {
+		if f, err := os.OpenFile(e.AppConfig.LogErrorFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666); err == nil {
+			e.Logger.SetErrorOutput(f)
+		}
+	}
+	
+	// Tag every entry with the application name if configured
+	if e.AppConfig.AppName != "" {
+		e.Logger = e.Logger.WithService(e.AppConfig.AppName)
+	}
+	
+	if levelErr != nil {
+		e.Logger.Warnf("invalid log_level, defaulting to INFO: %v", levelErr)
+	}
 }
 
 // InitLogger initializes the logger with the specified level.
@@ -121,6 +227,9 @@ func (e *Env) SetLogOutput(output *os.File) {
 }
 
 // GetConfig returns the configuration manager for direct access.
+// Reload re-reads its files, so do not use it while Reload or
+// HandleReloadSignals may run; read the standard configuration through
+// GetAppConfig instead.
 func (e *Env) GetConfig() *config.Loader {
 	return e.config
 }
@@ -130,8 +239,11 @@ func (e *Env) GetLogger() *logger.Logger {
 	return e.Logger
 }
 
-// GetAppConfig returns the standard application configuration.
+// GetAppConfig returns the standard application configuration. It is safe to
+// call while Reload runs; the returned Config is never modified afterwards.
 func (e *Env) GetAppConfig() *Config {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.AppConfig
 }
 
@@ -139,3 +251,173 @@ func (e *Env) GetAppConfig() *Config {
 func (e *Env) GetCustomConfig() interface{} {
 	return e.customConfig
 }
+
+// SetBuildInfo records the application's build information, typically injected
+// at build time with -ldflags. Empty values fall back to runtime build info.
+func (e *Env) SetBuildInfo(version, commit, buildTime string) {
+	e.buildInfo = server.VersionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+	}
+}
+
+// GetBuildInfo returns the build information set with SetBuildInfo.
+func (e *Env) GetBuildInfo() server.VersionInfo {
+	return e.buildInfo
+}
+
+// NewServer creates a web server for backend from AppConfig's host, port, and
+// timeouts, with the backend's logging and recovery middleware and its
+// low-level error log already using the Env's logger, at ErrorLevel for the
+// latter. An empty backend uses AppConfig.WebBackend. Call it after
+// LoadStandardConfig.
+//
+// Backends register themselves with web.RegisterBackend when their package is
+// imported. "stdlib" is always available; import the gorilla or fiber package
+// to select those, which keeps their dependencies out of programs that do not:
+//
+//	import _ "github.com/Waryway/Wayframe/internal/web/gorilla"
+//
+// There is no chi backend.
+func (e *Env) NewServer(backend string) (web.Server, error) {
+	appConfig := e.GetAppConfig()
+	if backend == "" {
+		backend = appConfig.WebBackend
+	}
+
+	newServer, ok := web.LookupBackend(backend)
+	if !ok {
+		return nil, fmt.Errorf("unknown web backend %q (registered: %s)", backend, strings.Join(web.Backends(), ", "))
+	}
+
+	cfg := web.Config{
+		Addr:         fmt.Sprintf("%s:%d", appConfig.Host, appConfig.Port),
+		ReadTimeout:  appConfig.ReadTimeout,
+		WriteTimeout: appConfig.WriteTimeout,
+		IdleTimeout:  appConfig.IdleTimeout,
+		ErrorLog:     log.New(e.Logger.Writer(logger.ErrorLevel), "", 0),
+	}
+	return newServer(cfg, e.Logger), nil
+}
+
+// Run starts srv with AppConfig.ShutdownTimeout and blocks until it stops,
+// logging the outcome through the Env's logger: an error at ErrorLevel if
+// serving failed, or a clean exit at InfoLevel. It returns the error, so a
+// main function can end with:
+//
+//	if err := e.Run(srv); err != nil {
+//	    os.Exit(1)
+//	}
+//
+// http.ErrServerClosed, returned by some backends after Shutdown, counts as a
+// clean exit. Once the outcome is logged, Run closes the log files with Close.
+func (e *Env) Run(srv web.Server) error {
+	defer e.Close()
+	e.Logger.Infof("Server listening on %s", srv.Addr())
+	err := srv.Start(e.GetAppConfig().ShutdownTimeout)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.Logger.Errorf("Server error: %v", err)
+		return err
+	}
+	e.Logger.Info("Server stopped")
+	return nil
+}
+
+// Close closes the log files opened for LogFile and LogErrorFile, flushing
+// them and releasing their descriptors. Call it as the application exits;
+// Run does so itself. With a server from pkg/server, register it as an exit
+// hook so it runs however the server stops:
+//
+//	srv.OnExit(func() { e.Close() })
+//
+// Entries logged after Close are written to stderr.
+func (e *Env) Close() error {
+	return e.Logger.Close()
+}
+
+// VersionHandler returns an http.Handler serving the build information as JSON.
+func (e *Env) VersionHandler() http.Handler {
+	return server.VersionHandler(e.buildInfo)
+}
+
+// OnReload registers fn to be called after Reload has re-loaded the standard
+// configuration, such as when the process receives SIGHUP. Callbacks run in
+// registration order.
+func (e *Env) OnReload(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reloaders = append(e.reloaders, fn)
+}
+
+// Reload re-reads the config files, loads the standard configuration again
+// into a new Config, replaces AppConfig with it, and then calls each OnReload
+// callback. A key removed from a file falls back to its environment variable
+// or default. Callbacks are not called if loading fails, and a file that cannot
+// be read is returned as an error with the previous configuration kept. A
+// changed log level is applied to the existing Logger with SetLevel, so loggers
+// derived from it, such as those held by middleware, pick it up too. The
+// logger's files and service name are kept; changes to log_file,
+// log_error_file, or app_name are logged as a warning and take effect on
+// restart.
+func (e *Env) Reload() error {
+	e.mu.Lock()
+	if err := e.config.Reload(); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	cfg := &Config{}
+	if err := e.config.Load(cfg); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	prev := e.AppConfig
+	e.AppConfig = cfg
+	reloaders := append([]func(){}, e.reloaders...)
+	e.mu.Unlock()
+
+	level, err := logger.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		e.Logger.Warnf("invalid log_level, defaulting to INFO: %v", err)
+	}
+	e.Logger.SetLevel(level)
+	if cfg.LogFile != prev.LogFile || cfg.LogErrorFile != prev.LogErrorFile || cfg.AppName != prev.AppName {
+		e.Logger.Warn("log_file, log_error_file, and app_name changes take effect on restart")
+	}
+
+	for _, fn := range reloaders {
+		fn()
+	}
+	return nil
+}
+
+// HandleReloadSignals calls Reload each time the process receives SIGHUP,
+// until ctx is done. It returns immediately; signals are handled in the
+// background. SIGINT and SIGTERM are left to the server's shutdown handling.
+func (e *Env) HandleReloadSignals(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigs)
+		e.handleReloadSignals(ctx, sigs)
+	}()
+}
+
+// handleReloadSignals reloads on each SIGHUP received from sigs until ctx is done.
+func (e *Env) handleReloadSignals(ctx context.Context, sigs <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigs:
+			if sig != syscall.SIGHUP {
+				continue
+			}
+			if err := e.Reload(); err != nil {
+				e.Logger.Errorf("config reload failed: %v", err)
+				continue
+			}
+			e.Logger.Infof("configuration reloaded")
+		}
+	}
+}
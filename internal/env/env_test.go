@@ -1,9 +1,25 @@
 package env
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/Waryway/Wayframe/internal/web"
+	fiberserver "github.com/Waryway/Wayframe/internal/web/fiber"
+	gorillaserver "github.com/Waryway/Wayframe/internal/web/gorilla"
+	"github.com/Waryway/Wayframe/internal/web/stdlib"
+	"github.com/Waryway/Wayframe/pkg/config"
+	"github.com/Waryway/Wayframe/pkg/server"
 )
 
 func TestLoadStandardConfig(t *testing.T) {
@@ -151,3 +167,494 @@ func TestTimeoutDefaults(t *testing.T) {
 		t.Errorf("expected shutdown timeout 30s, got %v", e.AppConfig.ShutdownTimeout)
 	}
 }
+
+func TestVersionHandler(t *testing.T) {
+	e := New("")
+	e.SetBuildInfo("1.0.0", "deadbeef", "")
+
+	w := httptest.NewRecorder()
+	e.VersionHandler().ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"version":"1.0.0"`) {
+		t.Errorf("expected version in response, got %s", body)
+	}
+	if !strings.Contains(body, `"commit":"deadbeef"`) {
+		t.Errorf("expected commit in response, got %s", body)
+	}
+}
+
+func TestDescribeStandardConfig(t *testing.T) {
+	e := New("APP")
+
+	var port *config.FieldDoc
+	docs := e.GetConfig().DescribeStruct(e.AppConfig)
+	for i := range docs {
+		if docs[i].Name == "Port" {
+			port = &docs[i]
+		}
+	}
+
+	if port == nil {
+		t.Fatal("expected a descriptor for Port")
+	}
+	if port.EnvVar != "APP_PORT" {
+		t.Errorf("expected env var APP_PORT, got %s", port.EnvVar)
+	}
+	if port.Default != "8080" {
+		t.Errorf("expected default 8080, got %s", port.Default)
+	}
+}
+
+func TestEnvVarNames(t *testing.T) {
+	type DBConfig struct {
+		Host string `config:"host"`
+		Port int    `config:"port"`
+	}
+	type CustomConfig struct {
+		DatabaseURL string   `config:"database_url"`
+		Token       string   `config:"token" env:"SERVICE_TOKEN"`
+		Port        int      `config:"port"`
+		DB          DBConfig `config:"db"`
+	}
+
+	e := New("APP")
+	if err := e.LoadConfig(&CustomConfig{}); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	names := e.EnvVarNames()
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	for _, want := range []string{"APP_PORT", "APP_LOG_LEVEL", "APP_DATABASE_URL", "SERVICE_TOKEN", "APP_DB_HOST", "APP_DB_PORT"} {
+		if counts[want] != 1 {
+			t.Errorf("expected %s exactly once, got %d in %v", want, counts[want], names)
+		}
+	}
+	if counts["APP_DB"] != 0 {
+		t.Errorf("expected no variable for the nested struct itself, got %v", names)
+	}
+	if names[0] != "APP_PORT" {
+		t.Errorf("expected names in field order starting with APP_PORT, got %v", names)
+	}
+}
+
+func TestLogEffectiveConfig(t *testing.T) {
+	type CacheConfig struct {
+		TTL time.Duration `config:"ttl" default:"1m"`
+	}
+	type CustomConfig struct {
+		DatabaseURL string      `config:"database_url" default:"postgres://localhost/app"`
+		APIKey      string      `config:"api_key" default:"super-secret" secret:"true"`
+		Cache       CacheConfig `config:"cache"`
+	}
+
+	os.Setenv("APP_PORT", "9090")
+	defer os.Unsetenv("APP_PORT")
+
+	e := New("APP")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+	var custom CustomConfig
+	if err := e.LoadConfig(&custom); err != nil {
+		t.Fatalf("failed to load custom config: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	e.InitLoggerWithHandler(slog.NewTextHandler(buf, nil))
+	e.LogEffectiveConfig()
+
+	output := buf.String()
+	if strings.Count(output, "\n") != 1 {
+		t.Errorf("expected a single log line, got: %s", output)
+	}
+	for _, want := range []string{
+		"config.port.value=9090",
+		"config.port.source=env",
+		"config.host.source=default",
+		"config.database_url.value=postgres://localhost/app",
+		"config.api_key.value=[REDACTED]",
+		"config.cache.ttl.value=1m0s",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "super-secret") {
+		t.Errorf("secret value should be redacted: %s", output)
+	}
+}
+
+func TestInitLoggerWithAppName(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	os.Setenv("APP_APP_NAME", "orders")
+	os.Setenv("APP_LOG_FILE", logPath)
+	defer os.Unsetenv("APP_APP_NAME")
+	defer os.Unsetenv("APP_LOG_FILE")
+
+	e := New("APP")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+
+	e.Logger.Info("hello")
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "service=orders") {
+		t.Errorf("expected service field from app name, got: %s", data)
+	}
+}
+
+func TestInitLoggerInvalidLevel(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+
+	e := New("")
+	e.AppConfig.LogLevel = "DEGUB"
+	e.AppConfig.LogFile = logPath
+	e.InitLoggerFromConfig()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "level=WARN") || !strings.Contains(string(data), "DEGUB") {
+		t.Errorf("expected a warning about the invalid level, got: %s", data)
+	}
+}
+
+func TestClose(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_FILE", logPath)
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	srv := server.New(server.Config{Addr: "127.0.0.1:0", DisableSignalHandling: true})
+	srv.OnExit(func() { e.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := srv.StartContext(ctx, time.Second); err != nil {
+		t.Fatalf("StartContext failed: %v", err)
+	}
+
+	fallback := &bytes.Buffer{}
+	e.Logger.SetFallbackOutput(fallback)
+	e.Logger.Info("after close")
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Contains(string(data), "after close") || !strings.Contains(fallback.String(), "after close") {
+		t.Errorf("expected the log file to be closed by the exit hook, got file: %s", data)
+	}
+}
+
+func TestInitLoggerErrorFile(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	errPath := filepath.Join(dir, "error.log")
+	t.Setenv("LOG_FILE", logPath)
+	t.Setenv("LOG_ERROR_FILE", errPath)
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	e.Logger.Info("request served")
+	e.Logger.Error("request failed")
+
+	main, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	errs, err := os.ReadFile(errPath)
+	if err != nil {
+		t.Fatalf("failed to read error log file: %v", err)
+	}
+	if !strings.Contains(string(main), "request served") || strings.Contains(string(main), "request failed") {
+		t.Errorf("expected only the info entry in the main log, got: %s", main)
+	}
+	if !strings.Contains(string(errs), "request failed") || strings.Contains(string(errs), "request served") {
+		t.Errorf("expected only the error entry in the error log, got: %s", errs)
+	}
+}
+
+func TestReload(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_FILE", logPath)
+	t.Setenv("LOG_LEVEL", "INFO")
+	t.Setenv("SHUTDOWN_TIMEOUT", "30s")
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+	requestLog := e.Logger.WithField("component", "http")
+	before := e.GetAppConfig()
+
+	t.Setenv("SHUTDOWN_TIMEOUT", "5s")
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	if err := e.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if got := e.GetAppConfig().ShutdownTimeout; got != 5*time.Second {
+		t.Errorf("expected reloaded shutdown timeout 5s, got %v", got)
+	}
+	if before.ShutdownTimeout != 30*time.Second {
+		t.Errorf("expected the previous config to be left unchanged, got %v", before.ShutdownTimeout)
+	}
+	requestLog.Debug("debug after reload")
+	if err := e.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "debug after reload") {
+		t.Errorf("expected the reloaded level to apply to derived loggers, got: %s", data)
+	}
+	fallback := &bytes.Buffer{}
+	e.Logger.SetFallbackOutput(fallback)
+	requestLog.Info("after close")
+	if !strings.Contains(fallback.String(), "after close") {
+		t.Errorf("expected Close to close the log file opened before the reload, got: %s", fallback.String())
+	}
+}
+
+func TestReloadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("shutdown_timeout: 5s\nenvironment: staging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RELOADTEST_CONFIG_FILE", path)
+
+	e := New("RELOADTEST")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+	if got := e.GetAppConfig().ShutdownTimeout; got != 5*time.Second {
+		t.Fatalf("expected shutdown timeout 5s from the file, got %v", got)
+	}
+
+	// A key removed from the file falls back to its default
+	if err := os.WriteFile(path, []byte("environment: staging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := e.GetAppConfig().ShutdownTimeout; got != 30*time.Second {
+		t.Errorf("expected shutdown timeout to fall back to the default 30s, got %v", got)
+	}
+
+	// A file that can no longer be read fails the reload and keeps the previous config
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err == nil {
+		t.Error("expected Reload to fail when the config file is gone")
+	}
+	if got := e.GetAppConfig().Environment; got != "staging" {
+		t.Errorf("expected the previous config to be kept, got environment %q", got)
+	}
+}
+
+func TestLoadStandardConfigMissingFile(t *testing.T) {
+	t.Setenv("RELOADTEST_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	if err := New("RELOADTEST").LoadStandardConfig(); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+func TestHandleReloadSignalsInjected(t *testing.T) {
+	t.Setenv("LOG_FILE", filepath.Join(t.TempDir(), "app.log"))
+	t.Setenv("LOG_LEVEL", "INFO")
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+
+	reloaded := make(chan string, 2)
+	e.OnReload(func() { reloaded <- e.AppConfig.LogLevel })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	go e.handleReloadSignals(ctx, sigs)
+
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	sigs <- syscall.SIGHUP
+	select {
+	case level := <-reloaded:
+		if level != "DEBUG" {
+			t.Errorf("expected reloaded log level DEBUG, got %s", level)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload callback did not fire on SIGHUP")
+	}
+
+	sigs <- syscall.SIGTERM
+	select {
+	case <-reloaded:
+		t.Error("expected SIGTERM not to trigger a reload")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandleReloadSignals(t *testing.T) {
+	t.Setenv("LOG_FILE", filepath.Join(t.TempDir(), "app.log"))
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+	reloaded := make(chan struct{}, 1)
+	e.OnReload(func() { reloaded <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.HandleReloadSignals(ctx)
+
+	srv := server.New(server.Config{Addr: "127.0.0.1:0"})
+	ready := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.StartWithReady(time.Second, ready) }()
+	<-ready
+
+	proc, _ := os.FindProcess(os.Getpid())
+	if err := proc.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("sending signals is not supported on this platform: %v", err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload callback did not fire on SIGHUP")
+	}
+
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("expected clean shutdown on SIGTERM, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down on SIGTERM")
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	t.Setenv("HOST", "127.0.0.1")
+	t.Setenv("PORT", "9099")
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+
+	srv, err := e.NewServer("stdlib")
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if srv.Addr() != "127.0.0.1:9099" {
+		t.Errorf("expected addr 127.0.0.1:9099, got %s", srv.Addr())
+	}
+	if _, ok := srv.(*stdlib.Server); !ok {
+		t.Errorf("expected a stdlib server, got %T", srv)
+	}
+
+	if _, err := e.NewServer("chi"); err == nil {
+		t.Error("expected error for an unknown backend")
+	}
+}
+
+func TestNewServerBackendFromConfig(t *testing.T) {
+	t.Setenv("WEB_BACKEND", "gorilla")
+
+	e := New("")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+
+	srv, err := e.NewServer("")
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if _, ok := srv.(*gorillaserver.Server); !ok {
+		t.Errorf("expected a gorilla server from web_backend, got %T", srv)
+	}
+
+	// Importing a backend package is enough to select it by configuration
+	t.Setenv("APP_WEB_BACKEND", "fiber")
+	e = New("APP")
+	if err := e.LoadStandardConfig(); err != nil {
+		t.Fatalf("failed to load standard config: %v", err)
+	}
+	srv, err = e.NewServer("")
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if _, ok := srv.(*fiberserver.Server); !ok {
+		t.Errorf("expected a fiber server from APP_WEB_BACKEND, got %T", srv)
+	}
+}
+
+// fakeServer is a web.Server whose Start returns a fixed error.
+type fakeServer struct {
+	web.Server
+	err     error
+	timeout time.Duration
+}
+
+func (s *fakeServer) Start(shutdownTimeout time.Duration) error {
+	s.timeout = shutdownTimeout
+	return s.err
+}
+
+func (s *fakeServer) Addr() string {
+	return "127.0.0.1:8080"
+}
+
+func TestRun(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := New("APP")
+	e.AppConfig.ShutdownTimeout = 5 * time.Second
+	e.Logger.SetOutput(buf)
+
+	srv := &fakeServer{err: errors.New("listen tcp: address already in use")}
+	if err := e.Run(srv); err != srv.err {
+		t.Errorf("expected Run to return the server error, got %v", err)
+	}
+	if srv.timeout != 5*time.Second {
+		t.Errorf("expected ShutdownTimeout to be passed to Start, got %v", srv.timeout)
+	}
+	if output := buf.String(); !strings.Contains(output, "level=ERROR") || !strings.Contains(output, "address already in use") {
+		t.Errorf("expected server error to be logged at ERROR, got: %s", output)
+	}
+
+	for _, clean := range []error{nil, http.ErrServerClosed} {
+		buf.Reset()
+		if err := e.Run(&fakeServer{err: clean}); err != nil {
+			t.Errorf("expected clean exit for %v, got %v", clean, err)
+		}
+		if output := buf.String(); strings.Contains(output, "level=ERROR") || !strings.Contains(output, "Server stopped") {
+			t.Errorf("expected clean exit to be logged at INFO for %v, got: %s", clean, output)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Logger is the logging interface backends give their logging and recovery
+// middleware. *logger.Logger satisfies it.
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Backend builds a Server from cfg with the backend's logging and recovery
+// middleware already using l.
+type Backend func(cfg Config, l Logger) Server
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Backend)
+)
+
+// RegisterBackend makes a backend available by name, typically from the init
+// function of the package implementing it, so importing that package is all a
+// program needs to select it by configuration. It panics if b is nil or name
+// is already registered.
+func RegisterBackend(name string, b Backend) {
+	if b == nil {
+		panic(fmt.Sprintf("web: nil backend %q", name))
+	}
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, dup := backends[name]; dup {
+		panic(fmt.Sprintf("web: backend %q registered twice", name))
+	}
+	backends[name] = b
+}
+
+// LookupBackend returns the backend registered under name.
+func LookupBackend(name string) (Backend, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	b, ok := backends[name]
+	return b, ok
+}
+
+// Backends returns the names of the registered backends in sorted order.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegisterBackend(t *testing.T) {
+	b := func(cfg Config, l Logger) Server { return nil }
+	RegisterBackend("test", b)
+
+	if _, ok := LookupBackend("test"); !ok {
+		t.Error("expected the registered backend to be found")
+	}
+	if _, ok := LookupBackend("chi"); ok {
+		t.Error("expected no backend for an unregistered name")
+	}
+	if !slices.Contains(Backends(), "test") {
+		t.Errorf("expected Backends to list test, got %v", Backends())
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected RegisterBackend to panic for a duplicate name")
+		}
+	}()
+	RegisterBackend("test", b)
+}
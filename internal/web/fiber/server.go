@@ -3,10 +3,13 @@ package fiber
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
 	"github.com/Waryway/Wayframe/internal/web"
 )
 
@@ -16,6 +19,14 @@ type Server struct {
 	addr string
 }
 
+func init() {
+	web.RegisterBackend("fiber", func(cfg web.Config, l web.Logger) web.Server {
+		srv := New(cfg)
+		srv.Use(LoggingMiddleware(l), RecoveryMiddleware(l))
+		return srv
+	})
+}
+
 // New creates a new Fiber server with the given configuration.
 func New(cfg web.Config) web.Server {
 	app := fiber.New(fiber.Config{
@@ -23,6 +34,9 @@ func New(cfg web.Config) web.Server {
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
 	})
+	if cfg.ErrorLog != nil {
+		logErrors(app.Server(), cfg.ErrorLog)
+	}
 	
 	return &Server{
 		app:  app,
@@ -30,26 +44,61 @@ func New(cfg web.Config) web.Server {
 	}
 }
 
+// logErrors sends fasthttp's own log output to errorLog, along with errors
+// reading requests, such as malformed requests, which Fiber otherwise answers
+// without logging.
+func logErrors(server *fasthttp.Server, errorLog *log.Logger) {
+	server.Logger = errorLog
+	handleError := server.ErrorHandler
+	server.ErrorHandler = func(ctx *fasthttp.RequestCtx, err error) {
+		errorLog.Printf("fasthttp: error serving request from %s: %v", ctx.RemoteAddr(), err)
+		if handleError != nil {
+			handleError(ctx, err)
+		}
+	}
+}
+
 // Use adds middleware to the server.
+// Middleware of unsupported types is ignored; use UseE to detect it.
 func (s *Server) Use(middleware ...interface{}) {
+	_ = s.UseE(middleware...)
+}
+
+// UseE adds middleware to the server, returning an error for each
+// middleware of an unsupported type, such as net/http middleware.
+func (s *Server) UseE(middleware ...interface{}) error {
+	var errs []error
 	for _, mw := range middleware {
 		if m, ok := mw.(func(*fiber.Ctx) error); ok {
 			s.app.Use(m)
 		} else if m, ok := mw.(fiber.Handler); ok {
 			s.app.Use(m)
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %T", web.ErrUnsupportedMiddleware, mw))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 // Handle registers a handler for the given pattern.
+// It panics if the handler type is unsupported; use HandleE to get an error instead.
 func (s *Server) Handle(pattern string, handler interface{}) {
+	if err := s.HandleE(pattern, handler); err != nil {
+		panic(err.Error())
+	}
+}
+
+// HandleE registers a handler for the given pattern, returning an error
+// if the handler type is unsupported.
+func (s *Server) HandleE(pattern string, handler interface{}) error {
 	if h, ok := handler.(func(*fiber.Ctx) error); ok {
 		s.app.All(pattern, h)
 	} else if h, ok := handler.(fiber.Handler); ok {
 		s.app.All(pattern, h)
 	} else {
-		panic(fmt.Sprintf("unsupported handler type: %T", handler))
+		return fmt.Errorf("%w: %T", web.ErrUnsupportedHandler, handler)
 	}
+	return nil
 }
 
 // HandleFunc registers a handler function for the given pattern.
@@ -57,6 +106,12 @@ func (s *Server) HandleFunc(pattern string, handlerFunc interface{}) {
 	s.Handle(pattern, handlerFunc)
 }
 
+// HandleFuncE registers a handler function for the given pattern, returning
+// an error if the handler type is unsupported.
+func (s *Server) HandleFuncE(pattern string, handlerFunc interface{}) error {
+	return s.HandleE(pattern, handlerFunc)
+}
+
 // Start starts the Fiber server and blocks until shutdown.
 func (s *Server) Start(shutdownTimeout time.Duration) error {
 	errChan := make(chan error, 1)
@@ -0,0 +1,113 @@
+package fiber
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/Waryway/Wayframe/internal/web"
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+func TestHandleEUnsupportedHandler(t *testing.T) {
+	srv := New(web.Config{Addr: ":0"})
+
+	if err := srv.HandleE("/bad", 42); !errors.Is(err, web.ErrUnsupportedHandler) {
+		t.Errorf("expected ErrUnsupportedHandler, got %v", err)
+	}
+	if err := srv.HandleFuncE("/bad", 42); !errors.Is(err, web.ErrUnsupportedHandler) {
+		t.Errorf("expected ErrUnsupportedHandler, got %v", err)
+	}
+	if err := srv.HandleE("/good", func(c *fiber.Ctx) error { return nil }); err != nil {
+		t.Errorf("expected no error for a fiber handler, got %v", err)
+	}
+}
+
+func TestUseEUnsupportedMiddleware(t *testing.T) {
+	srv := New(web.Config{Addr: ":0"})
+
+	if err := srv.UseE(func(c *fiber.Ctx) error { return c.Next() }); err != nil {
+		t.Errorf("expected fiber middleware to be accepted, got %v", err)
+	}
+
+	httpMiddleware := func(next http.Handler) http.Handler { return next }
+	if err := srv.UseE(httpMiddleware); !errors.Is(err, web.ErrUnsupportedMiddleware) {
+		t.Errorf("expected ErrUnsupportedMiddleware for net/http middleware, got %v", err)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use, written by the server
+// goroutines and read by the test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestErrorLogMalformedRequest(t *testing.T) {
+	out := &syncBuffer{}
+	l := logger.New(logger.InfoLevel)
+	l.SetOutput(out)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	srv := New(web.Config{Addr: addr, ErrorLog: log.New(l.Writer(logger.ErrorLevel), "", 0)})
+	go srv.Start(time.Second)
+	defer srv.Shutdown(context.Background())
+
+	var conn net.Conn
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if conn, err = net.Dial("tcp", addr); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start listening on %s", addr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: example.com\r\nContent-Length: nope\r\n\r\n")
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if !strings.Contains(status, "400") {
+		t.Errorf("expected 400 response, got %q", status)
+	}
+	// The error may be logged after the response is written
+	deadline = time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), "error serving request") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if output := out.String(); !strings.Contains(output, "level=ERROR") || !strings.Contains(output, "error serving request") {
+		t.Errorf("expected the malformed request to be logged at ERROR through the logger, got: %s", output)
+	}
+}
@@ -3,6 +3,7 @@ package gorilla
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -22,6 +23,14 @@ type Server struct {
 	addr       string
 }
 
+func init() {
+	web.RegisterBackend("gorilla", func(cfg web.Config, l web.Logger) web.Server {
+		srv := New(cfg)
+		srv.Use(LoggingMiddleware(l), RecoveryMiddleware(l))
+		return srv
+	})
+}
+
 // New creates a new Gorilla Mux server with the given configuration.
 func New(cfg web.Config) web.Server {
 	router := mux.NewRouter()
@@ -33,6 +42,7 @@ func New(cfg web.Config) web.Server {
 			ReadTimeout:  cfg.ReadTimeout,
 			WriteTimeout: cfg.WriteTimeout,
 			IdleTimeout:  cfg.IdleTimeout,
+			ErrorLog:     cfg.ErrorLog,
 		},
 		router:     router,
 		middleware: make([]mux.MiddlewareFunc, 0),
@@ -41,18 +51,40 @@ func New(cfg web.Config) web.Server {
 }
 
 // Use adds middleware to the server.
+// Middleware of unsupported types is ignored; use UseE to detect it.
 func (s *Server) Use(middleware ...interface{}) {
+	_ = s.UseE(middleware...)
+}
+
+// UseE adds middleware to the server, returning an error for each
+// middleware of an unsupported type.
+func (s *Server) UseE(middleware ...interface{}) error {
+	var errs []error
 	for _, mw := range middleware {
 		if m, ok := mw.(mux.MiddlewareFunc); ok {
 			s.router.Use(m)
 		} else if m, ok := mw.(func(http.Handler) http.Handler); ok {
 			s.router.Use(mux.MiddlewareFunc(m))
+		} else if m, ok := mw.(web.Middleware); ok {
+			s.router.Use(mux.MiddlewareFunc(m))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %T", web.ErrUnsupportedMiddleware, mw))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 // Handle registers a handler for the given pattern.
+// It panics if the handler type is unsupported; use HandleE to get an error instead.
 func (s *Server) Handle(pattern string, handler interface{}) {
+	if err := s.HandleE(pattern, handler); err != nil {
+		panic(err.Error())
+	}
+}
+
+// HandleE registers a handler for the given pattern, returning an error
+// if the handler type is unsupported.
+func (s *Server) HandleE(pattern string, handler interface{}) error {
 	if h, ok := handler.(http.Handler); ok {
 		s.router.Handle(pattern, h)
 	} else if h, ok := handler.(http.HandlerFunc); ok {
@@ -60,36 +92,50 @@ func (s *Server) Handle(pattern string, handler interface{}) {
 	} else if h, ok := handler.(func(http.ResponseWriter, *http.Request)); ok {
 		s.router.HandleFunc(pattern, h)
 	} else {
-		panic(fmt.Sprintf("unsupported handler type: %T", handler))
+		return fmt.Errorf("%w: %T", web.ErrUnsupportedHandler, handler)
 	}
+	return nil
 }
 
 // HandleFunc registers a handler function for the given pattern.
+// It panics if the handler type is unsupported; use HandleFuncE to get an error instead.
 func (s *Server) HandleFunc(pattern string, handlerFunc interface{}) {
+	if err := s.HandleFuncE(pattern, handlerFunc); err != nil {
+		panic(err.Error())
+	}
+}
+
+// HandleFuncE registers a handler function for the given pattern, returning
+// an error if the handler type is unsupported.
+func (s *Server) HandleFuncE(pattern string, handlerFunc interface{}) error {
 	if h, ok := handlerFunc.(func(http.ResponseWriter, *http.Request)); ok {
 		s.router.HandleFunc(pattern, h)
 	} else if h, ok := handlerFunc.(http.HandlerFunc); ok {
 		s.router.Handle(pattern, h)
 	} else {
-		panic(fmt.Sprintf("unsupported handler function type: %T", handlerFunc))
+		return fmt.Errorf("%w: %T", web.ErrUnsupportedHandler, handlerFunc)
 	}
+	return nil
 }
 
 // Start starts the HTTP server and blocks until a shutdown signal is received.
 func (s *Server) Start(shutdownTimeout time.Duration) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
 	
 	errChan := make(chan error, 1)
 	
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
+		errChan <- s.httpServer.ListenAndServe()
 	}()
 	
 	select {
 	case err := <-errChan:
+		// ErrServerClosed means Shutdown was called directly
+		if err == http.ErrServerClosed {
+			return nil
+		}
 		return err
 	case sig := <-quit:
 		fmt.Printf("Received signal: %v, shutting down gracefully...\n", sig)
@@ -4,21 +4,36 @@ package web
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 )
 
 // Server represents a web server interface that all implementations must satisfy.
 type Server interface {
-	// Use adds middleware to the server
+	// Use adds middleware to the server, ignoring middleware of unsupported types
 	Use(middleware ...interface{})
 	
+	// UseE adds middleware to the server and returns an error wrapping
+	// ErrUnsupportedMiddleware for each middleware of an unsupported type.
+	// Supported middleware is still added.
+	UseE(middleware ...interface{}) error
+	
 	// Handle registers a handler for the given pattern
 	Handle(pattern string, handler interface{})
 	
 	// HandleFunc registers a handler function for the given pattern
 	HandleFunc(pattern string, handlerFunc interface{})
 	
+	// HandleE is like Handle but returns an error wrapping
+	// ErrUnsupportedHandler instead of panicking on an unsupported handler type
+	HandleE(pattern string, handler interface{}) error
+	
+	// HandleFuncE is like HandleFunc but returns an error wrapping
+	// ErrUnsupportedHandler instead of panicking on an unsupported handler type
+	HandleFuncE(pattern string, handlerFunc interface{}) error
+	
 	// Start starts the server and blocks until shutdown
 	Start(shutdownTimeout time.Duration) error
 	
@@ -29,12 +44,26 @@ type Server interface {
 	Addr() string
 }
 
+// ErrUnsupportedHandler is returned by HandleE and HandleFuncE when a handler's
+// type is not accepted by the server implementation.
+var ErrUnsupportedHandler = errors.New("unsupported handler type")
+
+// ErrUnsupportedMiddleware is returned by UseE when a middleware's type is not
+// accepted by the server implementation.
+var ErrUnsupportedMiddleware = errors.New("unsupported middleware type")
+
 // Config holds common configuration for web servers.
 type Config struct {
 	Addr         string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// ErrorLog receives low-level errors from the underlying server, such as
+	// connection errors and misbehaving handlers, that never reach middleware.
+	// If nil, each backend keeps its default. To route them into a Wayframe
+	// logger, use log.New(l.Writer(logger.ErrorLevel), "", 0).
+	ErrorLog *log.Logger
 }
 
 // Middleware is a generic middleware function type.
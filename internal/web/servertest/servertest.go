@@ -0,0 +1,277 @@
+// Package servertest provides a shared behavioral test suite for web.Server implementations.
+//
+// Backends that accept net/http handlers and func(http.Handler) http.Handler
+// middleware can verify they behave consistently by calling RunConformanceTests
+// from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//	    servertest.RunConformanceTests(t, New)
+//	}
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Waryway/Wayframe/internal/web"
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+// Factory creates a web.Server for the given configuration.
+type Factory func(web.Config) web.Server
+
+// RunConformanceTests exercises routing, middleware order, 404 handling,
+// unsupported handler and middleware types, graceful shutdown, and Config.ErrorLog
+// against servers created by factory.
+func RunConformanceTests(t *testing.T, factory Factory) {
+	t.Helper()
+
+	t.Run("Routing", func(t *testing.T) { testRouting(t, factory) })
+	t.Run("MiddlewareOrder", func(t *testing.T) { testMiddlewareOrder(t, factory) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, factory) })
+	t.Run("UnsupportedHandler", func(t *testing.T) { testUnsupportedHandler(t, factory) })
+	t.Run("UnsupportedMiddleware", func(t *testing.T) { testUnsupportedMiddleware(t, factory) })
+	t.Run("Shutdown", func(t *testing.T) { testShutdown(t, factory) })
+	t.Run("ErrorLog", func(t *testing.T) { testErrorLog(t, factory) })
+}
+
+func testRouting(t *testing.T, factory Factory) {
+	srv, base := startServer(t, factory, func(s web.Server) {
+		s.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "hello")
+		})
+		s.Handle("/handler", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "handler")
+		}))
+	})
+	defer stopServer(t, srv)
+
+	for path, want := range map[string]string{"/hello": "hello", "/handler": "handler"} {
+		status, body := get(t, base+path)
+		if status != http.StatusOK {
+			t.Errorf("GET %s: expected status 200, got %d", path, status)
+		}
+		if body != want {
+			t.Errorf("GET %s: expected body %q, got %q", path, want, body)
+		}
+	}
+}
+
+func testMiddlewareOrder(t *testing.T, factory Factory) {
+	order := make(chan string, 8)
+	tag := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order <- name + "-before"
+				next.ServeHTTP(w, r)
+				order <- name + "-after"
+			})
+		}
+	}
+
+	srv, base := startServer(t, factory, func(s web.Server) {
+		s.Use(tag("mw1"), tag("mw2"))
+		s.HandleFunc("/order", func(w http.ResponseWriter, r *http.Request) {
+			order <- "handler"
+		})
+	})
+	defer stopServer(t, srv)
+
+	if status, _ := get(t, base+"/order"); status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+
+	expected := []string{"mw1-before", "mw2-before", "handler", "mw2-after", "mw1-after"}
+	for i, want := range expected {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Errorf("at position %d: expected %s, got %s", i, want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func testNotFound(t *testing.T, factory Factory) {
+	srv, base := startServer(t, factory, func(s web.Server) {
+		s.HandleFunc("/exists", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	defer stopServer(t, srv)
+
+	if status, _ := get(t, base+"/missing"); status != http.StatusNotFound {
+		t.Errorf("expected status 404 for unregistered path, got %d", status)
+	}
+}
+
+func testUnsupportedHandler(t *testing.T, factory Factory) {
+	srv := factory(web.Config{Addr: freeAddr(t)})
+
+	if err := srv.HandleE("/bad", 42); !errors.Is(err, web.ErrUnsupportedHandler) {
+		t.Errorf("HandleE: expected ErrUnsupportedHandler, got %v", err)
+	}
+	if err := srv.HandleFuncE("/bad", 42); !errors.Is(err, web.ErrUnsupportedHandler) {
+		t.Errorf("HandleFuncE: expected ErrUnsupportedHandler, got %v", err)
+	}
+	if err := srv.HandleFuncE("/good", func(w http.ResponseWriter, r *http.Request) {}); err != nil {
+		t.Errorf("HandleFuncE: expected no error for a handler func, got %v", err)
+	}
+}
+
+func testUnsupportedMiddleware(t *testing.T, factory Factory) {
+	srv := factory(web.Config{Addr: freeAddr(t)})
+
+	passthrough := func(next http.Handler) http.Handler { return next }
+	if err := srv.UseE(passthrough, web.Middleware(passthrough)); err != nil {
+		t.Errorf("expected net/http middleware to be accepted, got %v", err)
+	}
+
+	incompatible := func(next func() error) func() error { return next }
+	err := srv.UseE(passthrough, incompatible)
+	if !errors.Is(err, web.ErrUnsupportedMiddleware) {
+		t.Errorf("expected ErrUnsupportedMiddleware for %T, got %v", incompatible, err)
+	}
+}
+
+func testShutdown(t *testing.T, factory Factory) {
+	srv, base := startServer(t, factory, func(s web.Server) {
+		s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	stopServer(t, srv)
+
+	client := &http.Client{Timeout: time.Second}
+	if resp, err := client.Get(base + "/"); err == nil {
+		resp.Body.Close()
+		t.Error("expected requests to fail after shutdown")
+	}
+}
+
+func testErrorLog(t *testing.T, factory Factory) {
+	out := &bytes.Buffer{}
+	log := logger.New(logger.InfoLevel)
+	log.SetOutput(out)
+	withErrorLog := func(cfg web.Config) web.Server {
+		cfg.ErrorLog = stdlog.New(log.Writer(logger.ErrorLevel), "", 0)
+		return factory(cfg)
+	}
+
+	srv, base := startServer(t, withErrorLog, func(s web.Server) {
+		s.HandleFunc("/twice", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+	if status, _ := get(t, base+"/twice"); status != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", status)
+	}
+	stopServer(t, srv)
+
+	output := out.String()
+	if !strings.Contains(output, "level=ERROR") || !strings.Contains(output, "superfluous response.WriteHeader") {
+		t.Errorf("expected the server error to be logged at ERROR through the logger, got: %s", output)
+	}
+}
+
+// running tracks a started server and the result of its Start call.
+type running struct {
+	server web.Server
+	done   chan error
+}
+
+// startServer creates a server on a free port, lets setup register routes,
+// starts it, and waits until it accepts connections.
+func startServer(t *testing.T, factory Factory, setup func(web.Server)) (*running, string) {
+	t.Helper()
+
+	addr := freeAddr(t)
+	srv := factory(web.Config{
+		Addr:         addr,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+		IdleTimeout:  5 * time.Second,
+	})
+	setup(srv)
+
+	r := &running{server: srv, done: make(chan error, 1)}
+	go func() {
+		r.done <- srv.Start(5 * time.Second)
+	}()
+
+	base := "http://" + addr
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return r, base
+		}
+		select {
+		case err := <-r.done:
+			t.Fatalf("server exited before accepting connections: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start listening on %s", addr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// stopServer shuts the server down and asserts Start returns cleanly.
+func stopServer(t *testing.T, r *running) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.server.Shutdown(ctx); err != nil {
+		t.Errorf("shutdown returned error: %v", err)
+	}
+
+	select {
+	case err := <-r.done:
+		if err != nil {
+			t.Errorf("Start returned error after shutdown: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Start did not return after shutdown")
+	}
+}
+
+// freeAddr returns a loopback address with a currently unused port.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// get performs a GET request and returns the status code and body.
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
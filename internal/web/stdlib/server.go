@@ -3,6 +3,7 @@ package stdlib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -21,6 +22,14 @@ type Server struct {
 	addr       string
 }
 
+func init() {
+	web.RegisterBackend("stdlib", func(cfg web.Config, l web.Logger) web.Server {
+		srv := New(cfg)
+		srv.Use(LoggingMiddleware(l), RecoveryMiddleware(l))
+		return srv
+	})
+}
+
 // New creates a new stdlib Server with the given configuration.
 func New(cfg web.Config) web.Server {
 	mux := http.NewServeMux()
@@ -32,6 +41,7 @@ func New(cfg web.Config) web.Server {
 			ReadTimeout:  cfg.ReadTimeout,
 			WriteTimeout: cfg.WriteTimeout,
 			IdleTimeout:  cfg.IdleTimeout,
+			ErrorLog:     cfg.ErrorLog,
 		},
 		mux:        mux,
 		middleware: make([]web.Middleware, 0),
@@ -40,18 +50,38 @@ func New(cfg web.Config) web.Server {
 }
 
 // Use adds middleware to the server.
+// Middleware of unsupported types is ignored; use UseE to detect it.
 func (s *Server) Use(middleware ...interface{}) {
+	_ = s.UseE(middleware...)
+}
+
+// UseE adds middleware to the server, returning an error for each
+// middleware of an unsupported type.
+func (s *Server) UseE(middleware ...interface{}) error {
+	var errs []error
 	for _, mw := range middleware {
 		if m, ok := mw.(web.Middleware); ok {
 			s.middleware = append(s.middleware, m)
 		} else if m, ok := mw.(func(http.Handler) http.Handler); ok {
 			s.middleware = append(s.middleware, web.Middleware(m))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %T", web.ErrUnsupportedMiddleware, mw))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 // Handle registers a handler for the given pattern.
+// It panics if the handler type is unsupported; use HandleE to get an error instead.
 func (s *Server) Handle(pattern string, handler interface{}) {
+	if err := s.HandleE(pattern, handler); err != nil {
+		panic(err.Error())
+	}
+}
+
+// HandleE registers a handler for the given pattern, returning an error
+// if the handler type is unsupported.
+func (s *Server) HandleE(pattern string, handler interface{}) error {
 	var h http.Handler
 	if hh, ok := handler.(http.Handler); ok {
 		h = hh
@@ -60,7 +90,7 @@ func (s *Server) Handle(pattern string, handler interface{}) {
 	} else if hf, ok := handler.(func(http.ResponseWriter, *http.Request)); ok {
 		h = http.HandlerFunc(hf)
 	} else {
-		panic(fmt.Sprintf("unsupported handler type: %T", handler))
+		return fmt.Errorf("%w: %T", web.ErrUnsupportedHandler, handler)
 	}
 	
 	// Apply middleware in reverse order
@@ -68,34 +98,46 @@ func (s *Server) Handle(pattern string, handler interface{}) {
 		h = s.middleware[i](h)
 	}
 	s.mux.Handle(pattern, h)
+	return nil
 }
 
 // HandleFunc registers a handler function for the given pattern.
+// It panics if the handler type is unsupported; use HandleFuncE to get an error instead.
 func (s *Server) HandleFunc(pattern string, handlerFunc interface{}) {
+	if err := s.HandleFuncE(pattern, handlerFunc); err != nil {
+		panic(err.Error())
+	}
+}
+
+// HandleFuncE registers a handler function for the given pattern, returning
+// an error if the handler type is unsupported.
+func (s *Server) HandleFuncE(pattern string, handlerFunc interface{}) error {
 	if hf, ok := handlerFunc.(func(http.ResponseWriter, *http.Request)); ok {
-		s.Handle(pattern, http.HandlerFunc(hf))
+		return s.HandleE(pattern, http.HandlerFunc(hf))
 	} else if hf, ok := handlerFunc.(http.HandlerFunc); ok {
-		s.Handle(pattern, hf)
-	} else {
-		panic(fmt.Sprintf("unsupported handler function type: %T", handlerFunc))
+		return s.HandleE(pattern, hf)
 	}
+	return fmt.Errorf("%w: %T", web.ErrUnsupportedHandler, handlerFunc)
 }
 
 // Start starts the HTTP server and blocks until a shutdown signal is received.
 func (s *Server) Start(shutdownTimeout time.Duration) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
 	
 	errChan := make(chan error, 1)
 	
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
+		errChan <- s.httpServer.ListenAndServe()
 	}()
 	
 	select {
 	case err := <-errChan:
+		// ErrServerClosed means Shutdown was called directly
+		if err == http.ErrServerClosed {
+			return nil
+		}
 		return err
 	case sig := <-quit:
 		fmt.Printf("Received signal: %v, shutting down gracefully...\n", sig)
@@ -0,0 +1,11 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/Waryway/Wayframe/internal/web/servertest"
+)
+
+func TestConformance(t *testing.T) {
+	servertest.RunConformanceTests(t, New)
+}
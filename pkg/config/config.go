@@ -3,10 +3,17 @@
 package config
 
 import (
+	"context"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -24,24 +31,261 @@ import (
 //	    LogLevel string `config:"log_level" env:"LOG_LEVEL" default:"INFO" file:"config.yaml"`
 //	}
 type Loader struct {
-	values    map[string]string
-	durations map[string]time.Duration
-	prefix    string
+	values        map[string]string
+	defaults      map[string]string // loaded with LoadDefaults
+	files         []string          // config files and downward API directories
+	downwardDirs  []string
+	remote        map[string]string
+	providers     []Provider
+	durations     map[string]time.Duration
+	prefix        string
+	fallbacks     []string
+	priority      []Source
+	allowEmpty    bool
+	caseSensitive bool
+	lenientJSON   bool
+	strict        bool
+	fields        map[string]fieldInfo
+	environment   string
+	envDelimiter  string
+	changes       map[string][2]string
 }
 
+// fieldInfo records how a struct field loaded by Load is resolved.
+type fieldInfo struct {
+	envKey       string
+	defaultValue string
+	secret       bool
+}
+
+// Source identifies where a configuration value was resolved from.
+type Source int
+
+const (
+	// Env is a value read from an environment variable.
+	Env Source = iota
+	// File is a value read from a loaded configuration file.
+	File
+	// Remote is a value supplied by a Provider added with AddProvider.
+	Remote
+	// Default is a default value supplied in code or a struct tag.
+	Default
+)
+
+// String returns the lower-case name of the source.
+func (s Source) String() string {
+	switch s {
+	case Env:
+		return "env"
+	case File:
+		return "file"
+	case Remote:
+		return "remote"
+	case Default:
+		return "default"
+	default:
+		return "unknown"
+	}
+}
+
+// defaultEnvDelimiter stands for the dot of a nested key in environment
+// variable names, as in DB__HOST for db.host.
+const defaultEnvDelimiter = "__"
+
+// defaultPriority is the standard resolution order: env > remote > file > default.
+var defaultPriority = []Source{Env, Remote, File, Default}
+
 // New creates a new configuration loader with an optional prefix for environment variables.
 // The prefix is prepended to all environment variable names (e.g., "APP" -> "APP_PORT").
 func New(prefix string) *Loader {
 	return &Loader{
-		values:    make(map[string]string),
-		durations: make(map[string]time.Duration),
-		prefix:    strings.ToUpper(prefix),
+		values:       make(map[string]string),
+		defaults:     make(map[string]string),
+		remote:       make(map[string]string),
+		durations:    make(map[string]time.Duration),
+		prefix:       strings.ToUpper(prefix),
+		priority:     defaultPriority,
+		fields:       make(map[string]fieldInfo),
+		envDelimiter: defaultEnvDelimiter,
+	}
+}
+
+// SetPriority changes the order in which sources are consulted when resolving a value.
+// The default order is Env, Remote, File, Default. Sources omitted from order are never consulted.
+//
+// Placing File before Env makes configuration files authoritative, so a stray or
+// inherited environment variable cannot override them. Use this with care: it also
+// prevents operators from overriding file values through the environment, which is
+// the usual escape hatch during incidents.
+func (l *Loader) SetPriority(order []Source) {
+	l.priority = append([]Source(nil), order...)
+	// Cached durations may have been resolved with the previous order
+	l.durations = make(map[string]time.Duration)
+}
+
+// WithFallbackPrefix adds an environment variable prefix to try when the variable
+// with the primary prefix is unset. Fallbacks are tried in the order they were
+// added, before file values and defaults. An empty prefix falls back to the bare
+// key, which models service-specific overrides of cluster-wide settings:
+//
+//	cfg := config.New("APP").WithFallbackPrefix("")
+//	cfg.String("db_host", "") // APP_DB_HOST, then DB_HOST
+//
+// Fields with an explicit env tag use only that variable.
+func (l *Loader) WithFallbackPrefix(prefix string) *Loader {
+	l.fallbacks = append(l.fallbacks, strings.ToUpper(prefix))
+	l.durations = make(map[string]time.Duration)
+	return l
+}
+
+// WithEnvironment sets the environment used to select `default_{environment}`
+// struct tags in Load, overriding the "environment" configuration key.
+func (l *Loader) WithEnvironment(name string) *Loader {
+	l.environment = strings.ToLower(name)
+	return l
+}
+
+// environmentAliases maps environment names to the short forms also accepted
+// in default tags, so `default_prod` applies when the environment is "production".
+var environmentAliases = map[string]string{
+	"production":  "prod",
+	"development": "dev",
+}
+
+// activeEnvironment returns the environment set with WithEnvironment, or else
+// the value of the "environment" key from the environment, providers or files.
+func (l *Loader) activeEnvironment() string {
+	if l.environment != "" {
+		return l.environment
 	}
+	key := l.normalizeKey("environment")
+	val, _, _ := l.resolve(key, l.buildKey(key), "")
+	return strings.ToLower(val)
+}
+
+// fieldDefault returns the default value for field: its `default_{environment}`
+// tag for the active environment if present, or else its `default` tag. ok
+// reports whether either tag is present, even if empty.
+func (l *Loader) fieldDefault(field reflect.StructField) (value string, ok bool) {
+	if env := l.activeEnvironment(); env != "" {
+		if val, ok := field.Tag.Lookup("default_" + env); ok {
+			return val, true
+		}
+		if alias, ok := environmentAliases[env]; ok {
+			if val, ok := field.Tag.Lookup("default_" + alias); ok {
+				return val, true
+			}
+		}
+	}
+	return field.Tag.Lookup("default")
 }
 
 // LoadFile loads configuration from a file. Supports JSON, YAML, and key-value formats.
 // The format is auto-detected based on file extension or content.
+// Successfully loaded files are remembered so Watch can reload them.
 func (l *Loader) LoadFile(path string) error {
+	if err := l.readFile(path); err != nil {
+		return err
+	}
+	if !slices.Contains(l.files, path) {
+		l.files = append(l.files, path)
+	}
+	return nil
+}
+
+// configExtensions are the file extensions LoadDir treats as config files.
+var configExtensions = []string{".json", ".yaml", ".yml", ".env"}
+
+// LoadDir loads every .json, .yaml, .yml, and .env file in dir in lexical order
+// of file name, so values in later files override earlier ones (as with a
+// conf.d directory of numbered files). Other files and subdirectories are
+// skipped. Loading stops at the first file that fails.
+func (l *Loader) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read config directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(configExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := l.LoadFile(path); err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LoadDownwardAPI loads a directory of single-value files, such as pod
+// metadata mounted by the Kubernetes downward API at /etc/podinfo. Each file's
+// name is the key and its trimmed contents are the value, so the file
+// /etc/podinfo/namespace resolves as the key "namespace". Unlike LoadDir, files
+// are not parsed as config documents. Subdirectories and hidden entries, such
+// as the ..data links Kubernetes uses for atomic updates, are skipped. The
+// directory is remembered so Watch re-reads it along with the loaded files, in
+// the order they were loaded.
+func (l *Loader) LoadDownwardAPI(dir string) error {
+	if err := l.readDownwardAPI(dir); err != nil {
+		return err
+	}
+	if !slices.Contains(l.files, dir) {
+		l.files = append(l.files, dir)
+		l.downwardDirs = append(l.downwardDirs, dir)
+	}
+	return nil
+}
+
+// readDownwardAPI reads the files in dir into the values map.
+func (l *Loader) readDownwardAPI(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read downward API directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Mounted files are symlinks, so check the target rather than the entry
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		l.values[l.normalizeKey(entry.Name())] = strings.TrimSpace(string(data))
+	}
+	return nil
+}
+
+// LoadDefaults loads a file of default values, such as a defaults.yaml kept
+// alongside a sample config, instead of repeating defaults in struct tags. The
+// file is read like LoadFile but into a separate layer with the lowest
+// priority: environment variables, providers, and config files override it,
+// and it supplies values only where no default tag or default argument is
+// given. Its values resolve as the Default source. Later calls override keys
+// from earlier ones. Defaults files are not reloaded by Watch.
+func (l *Loader) LoadDefaults(path string) error {
+	// readFile fills the values map, so parse into a fresh one and move it aside
+	prev := l.values
+	l.values = make(map[string]string)
+	err := l.readFile(path)
+	loaded := l.values
+	l.values = prev
+	if err != nil {
+		return err
+	}
+	maps.Copy(l.defaults, loaded)
+	l.durations = make(map[string]time.Duration)
+	return nil
+}
+
+// readFile parses the file at path into the values map.
+func (l *Loader) readFile(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
@@ -70,6 +314,9 @@ func (l *Loader) LoadFile(path string) error {
 }
 
 func (l *Loader) loadJSON(data []byte) error {
+	if l.lenientJSON {
+		data = stripJSONExtensions(data)
+	}
 	var config map[string]interface{}
 	if err := json.Unmarshal(data, &config); err != nil {
 		return fmt.Errorf("failed to parse JSON: %w", err)
@@ -91,24 +338,73 @@ func (l *Loader) loadYAML(data []byte) error {
 
 func (l *Loader) loadKeyValue(data []byte) error {
 	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		// Lines from shell-sourced .env files may be exported
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// Remove quotes if present
-			value = strings.Trim(value, `"'`)
-			l.values[strings.ToUpper(key)] = value
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
 		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+
+		// A quoted value without its closing quote continues on the following lines
+		if isQuote(raw) && strings.IndexByte(raw[1:], raw[0]) < 0 {
+			quote := raw[0]
+			value := []string{raw[1:]}
+			closed := false
+			for i+1 < len(lines) {
+				i++
+				next := strings.TrimRight(lines[i], "\r")
+				if end := strings.IndexByte(next, quote); end >= 0 {
+					value = append(value, next[:end])
+					closed = true
+					break
+				}
+				value = append(value, next)
+			}
+			if !closed {
+				return fmt.Errorf("unterminated quoted value for key %s", key)
+			}
+			l.values[l.normalizeKey(key)] = strings.Join(value, "\n")
+			continue
+		}
+
+		l.values[l.normalizeKey(key)] = parseValue(raw)
 	}
 	return nil
 }
 
+// isQuote reports whether s starts with a single or double quote.
+func isQuote(s string) bool {
+	return s != "" && (s[0] == '"' || s[0] == '\'')
+}
+
+// parseValue extracts a single-line key-value file value. A value wrapped in
+// matching quotes is taken verbatim up to the closing quote, so it may contain
+// '#' or surrounding spaces. Otherwise an inline comment (a '#' preceded by
+// whitespace) is removed and the rest, including any '=', is kept intact.
+func parseValue(raw string) string {
+	if isQuote(raw) {
+		if end := strings.IndexByte(raw[1:], raw[0]); end >= 0 {
+			return raw[1 : end+1]
+		}
+	}
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return strings.TrimSpace(raw[:i])
+		}
+	}
+	return raw
+}
+
 func (l *Loader) flattenMap(prefix string, m map[string]interface{}) {
 	for k, v := range m {
 		key := k
@@ -119,49 +415,171 @@ func (l *Loader) flattenMap(prefix string, m map[string]interface{}) {
 		switch val := v.(type) {
 		case map[string]interface{}:
 			l.flattenMap(key, val)
+		case []interface{}:
+			// Lists are stored comma-separated, as StringSlice reads them
+			items := make([]string, len(val))
+			for i, item := range val {
+				items[i] = fmt.Sprintf("%v", item)
+			}
+			l.values[l.normalizeKey(key)] = strings.Join(items, ",")
 		default:
-			l.values[strings.ToUpper(key)] = fmt.Sprintf("%v", val)
+			l.values[l.normalizeKey(key)] = fmt.Sprintf("%v", val)
 		}
 	}
 }
 
+// AllowEmpty controls whether an explicitly set but empty value is honored.
+// By default an empty environment variable or file value is treated as unset
+// and resolution falls through to the next source. With AllowEmpty(true), a
+// variable that is present but empty resolves to "".
+func (l *Loader) AllowEmpty(allow bool) {
+	l.allowEmpty = allow
+}
+
+// CaseSensitive controls whether configuration keys keep their original casing.
+// By default keys are upper-cased, so "readTimeout" and "READTIMEOUT" are the same
+// key. In case-sensitive mode, file keys are stored and looked up exactly as written,
+// so camelCase YAML keys round-trip. Environment variable names are still upper-cased.
+//
+// Call CaseSensitive before loading files, since keys are normalized when a file is
+// read. With struct tags, the config tag must then match the file's casing exactly;
+// fields without a config tag use the lower-cased field name.
+func (l *Loader) CaseSensitive(enabled bool) {
+	l.caseSensitive = enabled
+}
+
+// StrictDefaults controls whether Load fails for fields that have no value
+// from any source and no default tag, instead of leaving them at their zero
+// value. It catches settings that were added to a struct but never wired up.
+// An explicitly empty tag, default:"", counts as a default. Load reports all
+// such fields in one error.
+func (l *Loader) StrictDefaults(enabled bool) {
+	l.strict = enabled
+}
+
+// LenientJSON controls whether JSON config files may contain // and /* */
+// comments and trailing commas, which are common in hand-edited files. Parsing
+// is strict by default. Call LenientJSON before loading files.
+func (l *Loader) LenientJSON(enabled bool) {
+	l.lenientJSON = enabled
+}
+
+// EnvDelimiter sets the separator that stands for the dot of a nested key in
+// environment variable names. When the usual variable for a nested key such as
+// db.host (APP_DB_HOST) is unset, the loader also checks APP_DB__HOST, the
+// twelve-factor convention for encoding nesting. The default delimiter is "__";
+// an empty delimiter disables the extra lookup. Fields with an env tag use only
+// that variable.
+func (l *Loader) EnvDelimiter(delim string) {
+	l.envDelimiter = delim
+	l.durations = make(map[string]time.Duration)
+}
+
 // String loads a string configuration value.
 // Priority: 1) Environment variable, 2) File value, 3) Default value.
 // The environment variable name matches the key name (with prefix if set).
 func (l *Loader) String(key, defaultValue string) string {
-	key = strings.ToUpper(key)
+	key = l.normalizeKey(key)
 
-	// Check environment variable first
-	envKey := l.buildKey(key)
-	if val := os.Getenv(envKey); val != "" {
-		return val
-	}
+	val, _, _ := l.resolve(key, l.buildKey(key), defaultValue)
+	return val
+}
 
-	// Check loaded file values
-	if val, ok := l.values[key]; ok {
-		return val
+// resolve looks up a value in each source according to the loader's priority.
+// key must already be normalized; envKey is the full environment variable name.
+// It returns the value, the source it came from, and whether any source matched.
+func (l *Loader) resolve(key, envKey, defaultValue string) (string, Source, bool) {
+	for _, src := range l.priority {
+		switch src {
+		case Env:
+			if val, ok := l.lookupEnv(key, envKey); ok {
+				return val, Env, true
+			}
+		case Remote:
+			if val, ok := l.remote[key]; ok && (val != "" || l.allowEmpty) {
+				return val, Remote, true
+			}
+		case File:
+			if val, ok := l.values[key]; ok && (val != "" || l.allowEmpty) {
+				return val, File, true
+			}
+		case Default:
+			if defaultValue != "" {
+				return defaultValue, Default, true
+			}
+			if val, ok := l.defaults[key]; ok && (val != "" || l.allowEmpty) {
+				return val, Default, true
+			}
+		}
 	}
+	return "", Default, false
+}
 
-	// Return default
-	return defaultValue
+// lookupEnv reads envKey from the environment. If it is unset and envKey is the
+// name derived from key, the name with the nested-key delimiter is tried, and
+// then, for names derived with the primary prefix, each fallback prefix in turn.
+func (l *Loader) lookupEnv(key, envKey string) (string, bool) {
+	if val, ok := os.LookupEnv(envKey); ok && (val != "" || l.allowEmpty) {
+		return val, true
+	}
+	if l.envDelimiter != "" && strings.Contains(key, ".") {
+		// Only derived names have a delimited form; an env tag is used alone
+		derived := l.buildKey(key)
+		if envKey == derived || envKey == strings.ReplaceAll(derived, ".", "_") {
+			name := l.buildKey(strings.ReplaceAll(key, ".", l.envDelimiter))
+			if val, ok := os.LookupEnv(name); ok && (val != "" || l.allowEmpty) {
+				return val, true
+			}
+		}
+	}
+	if envKey != l.buildKey(key) {
+		return "", false
+	}
+	for _, prefix := range l.fallbacks {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		if val, ok := os.LookupEnv(name); ok && (val != "" || l.allowEmpty) {
+			return val, true
+		}
+	}
+	return "", false
 }
 
 // Int loads an integer configuration value.
 // Priority: 1) Environment variable, 2) File value, 3) Default value.
-// Returns the default value if the value cannot be parsed.
+// Integral floats such as "8080.0", which some tools write for numbers in
+// JSON and YAML files, are accepted. Returns the default value if the value
+// cannot be parsed or has a fractional part.
 func (l *Loader) Int(key string, defaultValue int) int {
 	val := l.String(key, "")
 	if val == "" {
 		return defaultValue
 	}
 
-	if intVal, err := strconv.Atoi(val); err == nil {
-		return intVal
+	if intVal, err := parseInt(val, strconv.IntSize); err == nil {
+		return int(intVal)
 	}
 
 	return defaultValue
 }
 
+// parseInt parses s as a base-10 integer that fits in bitSize bits. If that
+// fails, a float without a fractional part, such as "8080.0", is accepted.
+func parseInt(s string, bitSize int) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, bitSize)
+	if err == nil {
+		return i, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	limit := math.Ldexp(1, bitSize-1)
+	if ferr != nil || f != math.Trunc(f) || f < -limit || f >= limit {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
 // Bool loads a boolean configuration value.
 // Priority: 1) Environment variable, 2) File value, 3) Default value.
 // Accepts: "true", "1", "yes", "on" (case-insensitive) as true.
@@ -185,11 +603,12 @@ func (l *Loader) Bool(key string, defaultValue bool) bool {
 
 // Duration loads a duration configuration value.
 // Priority: 1) Environment variable, 2) File value, 3) Default value.
-// Accepts values like "1s", "5m", "1h" as per time.ParseDuration.
+// Accepts values like "1s", "5m", "1h" as per time.ParseDuration, plus days
+// and weeks such as "7d", "2w", or "1w3d".
 // Returns the default value if the value cannot be parsed.
 // Successfully parsed durations from config sources are cached to avoid repeated parsing.
 func (l *Loader) Duration(key string, defaultValue time.Duration) time.Duration {
-	key = strings.ToUpper(key)
+	key = l.normalizeKey(key)
 
 	// Check if we already parsed this duration
 	if cached, ok := l.durations[key]; ok {
@@ -202,7 +621,7 @@ func (l *Loader) Duration(key string, defaultValue time.Duration) time.Duration
 		return defaultValue
 	}
 
-	duration, err := time.ParseDuration(val)
+	duration, err := parseDuration(val)
 	if err != nil {
 		// Parse error, return default without caching
 		return defaultValue
@@ -213,37 +632,186 @@ func (l *Loader) Duration(key string, defaultValue time.Duration) time.Duration
 	return duration
 }
 
+// DefaultSeparators are the characters StringSlice splits on by default:
+// comma, newline, and semicolon.
+const DefaultSeparators = ",\n;"
+
+// SliceOptions configures how StringSlice splits a value.
+type SliceOptions struct {
+	// Separators is the set of characters that separate elements.
+	// Defaults to DefaultSeparators.
+	Separators string
+}
+
+// StringSlice loads a list configuration value.
+// Priority: 1) Environment variable, 2) File value, 3) Default value.
+// The value is split on any of the separator characters, so a list can be
+// written as "a,b,c" or as a multiline environment variable with one element
+// per line. Elements are trimmed and empty elements are dropped. Returns the
+// default value if the key is unset or contains no elements.
+func (l *Loader) StringSlice(key string, defaultValue []string, opts ...SliceOptions) []string {
+	separators := DefaultSeparators
+	if len(opts) > 0 && opts[0].Separators != "" {
+		separators = opts[0].Separators
+	}
+
+	list := splitList(l.String(key, ""), separators)
+	if len(list) == 0 {
+		return defaultValue
+	}
+	return list
+}
+
+// splitList splits s on any character in separators, trimming elements and
+// dropping empty ones.
+func splitList(s, separators string) []string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	})
+	list := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
 // Required loads a required string configuration value.
 // Priority: 1) Environment variable, 2) File value.
 // Panics if the value is not set in either location.
 func (l *Loader) Required(key string) string {
 	val := l.String(key, "")
 	if val == "" {
-		envKey := l.buildKey(strings.ToUpper(key))
+		envKey := l.buildKey(key)
 		panic(fmt.Sprintf("required configuration %s is not set", envKey))
 	}
 	return val
 }
 
+// Source reports where the value for key currently resolves from: "env", "file",
+// or "default". For keys populated by Load, the field's env tag and default tag
+// are taken into account. It returns "" if the key is not set anywhere.
+func (l *Loader) Source(key string) string {
+	key = l.normalizeKey(key)
+
+	info, ok := l.fields[key]
+	if !ok {
+		info = fieldInfo{envKey: l.buildKey(key)}
+	}
+	if _, src, found := l.resolve(key, info.envKey, info.defaultValue); found {
+		return src.String()
+	}
+	return ""
+}
+
+// Raw returns the unparsed value configured for key and its source ("env",
+// "file", or "remote"), as an operator wrote it. Unlike String, defaults are
+// not applied: ok is false if key is not explicitly set. For keys populated by
+// Load, the field's env tag is taken into account. Values of fields tagged
+// secret:"true" are returned as is, so redact them before display.
+func (l *Loader) Raw(key string) (value string, source string, ok bool) {
+	key = l.normalizeKey(key)
+
+	envKey := l.buildKey(key)
+	if info, ok := l.fields[key]; ok {
+		envKey = info.envKey
+	}
+	// Values from a defaults file are defaults, not explicit settings
+	value, src, found := l.resolve(key, envKey, "")
+	if !found || src == Default {
+		return "", "", false
+	}
+	return value, src.String(), true
+}
+
+// IsSet reports whether key has an explicit value from the environment, a
+// loaded file, or a provider, ignoring defaults. Use it to enable optional
+// behavior only when an operator has configured it. For keys populated by Load,
+// the field's env tag is taken into account. An empty value counts as set only
+// with AllowEmpty(true), matching how values are resolved.
+func (l *Loader) IsSet(key string) bool {
+	_, _, ok := l.Raw(key)
+	return ok
+}
+
 // buildKey constructs the full environment variable name with prefix.
+// Environment variable names are always upper case, even in case-sensitive mode.
 func (l *Loader) buildKey(key string) string {
+	key = strings.ToUpper(key)
 	if l.prefix != "" {
 		return l.prefix + "_" + key
 	}
 	return key
 }
 
+// normalizeKey returns the form of key used in the values map:
+// upper case by default, or unchanged in case-sensitive mode.
+func (l *Loader) normalizeKey(key string) string {
+	if l.caseSensitive {
+		return key
+	}
+	return strings.ToUpper(key)
+}
+
+// AsMap returns a copy of all loaded file, provider, and defaults file values
+// with matching environment variables applied on top. Keys are normalized to upper case, and
+// the environment variable for each key is looked up with the loader's prefix.
+func (l *Loader) AsMap() map[string]string {
+	result := make(map[string]string, len(l.values)+len(l.remote)+len(l.defaults))
+	for key, val := range l.defaults {
+		result[key] = l.String(key, val)
+	}
+	for key, val := range l.values {
+		result[key] = l.String(key, val)
+	}
+	for key, val := range l.remote {
+		result[key] = l.String(key, val)
+	}
+	return result
+}
+
 // Load populates a struct with configuration values from files, environment variables, and defaults.
 // Uses struct tags: `config:"key"`, `env:"ENV_VAR"`, `default:"value"`, `file:"path"`
+// A `default_{environment}` tag, such as `default_prod:"80"`, replaces the default
+// when that environment is active (see WithEnvironment).
+// []string fields are split like StringSlice; a `sep:";"` tag overrides the separators.
+// time.Duration fields with a `unit:"s"` tag (or ns, us, ms, m, h, d, w) also
+// accept plain numbers in that unit, such as 10 for ten seconds.
+// If providers have been added with AddProvider, they are refreshed first.
+//
+// Values merge over defaults key by key. Nested struct fields are loaded field
+// by field under the parent's key (file key "db.host", environment variable
+// APP_DB_HOST for a field Host in a field DB), so a file that sets one sub-field
+// keeps the defaults of the others. A list is a single value: a list set in a
+// file or the environment replaces the default list as a whole.
 func (l *Loader) Load(configStruct interface{}) error {
 	v := reflect.ValueOf(configStruct)
 	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("config must be a pointer to a struct")
 	}
 
-	v = v.Elem()
-	t := v.Type()
+	if len(l.providers) > 0 {
+		if err := l.Refresh(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	var missing []error
+	if err := l.loadStruct(v.Elem(), "", "", &missing); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d config fields have no value and no default:\n%w", len(missing), errors.Join(missing...))
+	}
+	return nil
+}
 
+// loadStruct populates the fields of struct value v. For nested structs,
+// keyPrefix is the parent's key followed by "." and envPrefix is the parent's
+// environment variable name followed by "_"; both are empty at the top level.
+func (l *Loader) loadStruct(v reflect.Value, keyPrefix, envPrefix string, missing *[]error) error {
+	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
@@ -257,54 +825,95 @@ func (l *Loader) Load(configStruct interface{}) error {
 			l.LoadFile(filePath)
 		}
 
-		// Get configuration key
-		configKey := field.Tag.Get("config")
-		if configKey == "" {
-			configKey = strings.ToLower(field.Name)
+		// Get configuration key and environment variable name
+		key, envKey := l.fieldKeys(field, keyPrefix, envPrefix)
+		key = l.normalizeKey(key)
+
+		// Nested structs are loaded field by field, so a file that sets some
+		// sub-keys keeps the defaults of the others
+		if isNestedStruct(field.Type) {
+			if err := l.loadStruct(fieldValue, key+".", envKey+"_", missing); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// Handle time.Duration fields specially using Duration() method
-		if fieldValue.Kind() == reflect.Int64 && fieldValue.Type() == reflect.TypeOf(time.Duration(0)) {
-			defaultValue := field.Tag.Get("default")
+		// Get default value, preferring the active environment's variant
+		defaultValue, hasDefault := l.fieldDefault(field)
+
+		// Remember how this key resolves so Source can report it later
+		secret, _ := strconv.ParseBool(field.Tag.Get("secret"))
+		l.fields[key] = fieldInfo{envKey: envKey, defaultValue: defaultValue, secret: secret}
+
+		// Priority: env var > file > default, unless changed with SetPriority
+		value, source, found := l.resolve(key, envKey, defaultValue)
+		if l.strict && !found && !hasDefault {
+			// Map fields are assembled from sub-keys rather than resolved directly
+			isMap := fieldValue.Type() == reflect.TypeOf(map[string]string(nil))
+			if !isMap || len(l.loadMap(key, envKey, "")) == 0 {
+				*missing = append(*missing, fmt.Errorf("%s: not set (env %s)", key, envKey))
+			}
+		}
+
+		// Handle time.Duration fields specially, filling the Duration() cache.
+		// The cache is not read here, so loading again picks up changed values.
+		if fieldValue.Type() == reflect.TypeOf(time.Duration(0)) {
+			unit := field.Tag.Get("unit")
+			if _, ok := durationUnits[unit]; unit != "" && !ok {
+				return fmt.Errorf("invalid unit tag %q for field %s", unit, field.Name)
+			}
+
 			var defaultDur time.Duration
 			if defaultValue != "" {
 				var err error
-				defaultDur, err = time.ParseDuration(defaultValue)
+				defaultDur, err = parseDurationUnit(defaultValue, unit)
 				if err != nil {
 					return fmt.Errorf("failed to parse default duration for field %s: %w", field.Name, err)
 				}
-				// Store default in values so Duration() can cache it properly
-				upperKey := strings.ToUpper(configKey)
-				l.values[upperKey] = defaultValue
 			}
-			// Use Duration() method which handles priority and caching
-			dur := l.Duration(configKey, defaultDur)
+
+			if !found {
+				continue
+			}
+
+			// Unparseable config values fall back to the default, and only
+			// parsed config values are cached, as in Duration()
+			dur := defaultDur
+			if parsed, err := parseDurationUnit(value, unit); err == nil {
+				dur = parsed
+				if source != Default {
+					l.durations[key] = dur
+				}
+			}
 			fieldValue.SetInt(int64(dur))
 			continue
 		}
 
-		// Get environment variable name
-		envKey := field.Tag.Get("env")
-		if envKey == "" && l.prefix != "" {
-			envKey = l.prefix + "_" + strings.ToUpper(configKey)
-		} else if envKey == "" {
-			envKey = strings.ToUpper(configKey)
+		// Handle map[string]string fields from flattened sub-keys
+		if fieldValue.Type() == reflect.TypeOf(map[string]string(nil)) {
+			if m := l.loadMap(key, envKey, defaultValue); len(m) > 0 {
+				fieldValue.Set(reflect.ValueOf(m))
+			}
+			continue
 		}
 
-		// Get default value
-		defaultValue := field.Tag.Get("default")
-
-		// Priority: env var > file > default
-		var value string
-		if envVal := os.Getenv(envKey); envVal != "" {
-			value = envVal
-		} else if fileVal, ok := l.values[strings.ToUpper(configKey)]; ok {
-			value = fileVal
-		} else {
-			value = defaultValue
+		if value == "" {
+			// An explicit empty value only makes sense for string fields
+			if found && fieldValue.Kind() == reflect.String {
+				fieldValue.SetString("")
+			}
+			continue
 		}
 
-		if value == "" {
+		// Handle []string fields, splitting on the sep tag or the default separators
+		if fieldValue.Type() == reflect.TypeOf([]string(nil)) {
+			separators := field.Tag.Get("sep")
+			if separators == "" {
+				separators = DefaultSeparators
+			}
+			if list := splitList(value, separators); len(list) > 0 {
+				fieldValue.Set(reflect.ValueOf(list))
+			}
 			continue
 		}
 
@@ -317,13 +926,96 @@ func (l *Loader) Load(configStruct interface{}) error {
 	return nil
 }
 
+// fieldConfigKey returns the configuration key for a struct field,
+// taken from the config tag or the lower-cased field name.
+func fieldConfigKey(field reflect.StructField) string {
+	if configKey := field.Tag.Get("config"); configKey != "" {
+		return configKey
+	}
+	return strings.ToLower(field.Name)
+}
+
+// fieldKeys returns the configuration key and environment variable name for a
+// struct field nested under keyPrefix and envPrefix, which are empty at the top
+// level. Inside a nested struct, a field without an env tag is named after its
+// parent's variable, as in APP_DB_HOST.
+func (l *Loader) fieldKeys(field reflect.StructField, keyPrefix, envPrefix string) (key, envKey string) {
+	key = keyPrefix + fieldConfigKey(field)
+	envKey = l.fieldEnvKey(field, key)
+	if envPrefix != "" && field.Tag.Get("env") == "" {
+		envKey = envPrefix + strings.ToUpper(fieldConfigKey(field))
+	}
+	return key, envKey
+}
+
+// isNestedStruct reports whether fields of type t are loaded as a nested
+// struct, sub-key by sub-key, rather than parsed from a single value.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// fieldEnvKey returns the environment variable name for a struct field,
+// taken from the env tag or built from the key and the prefix.
+func (l *Loader) fieldEnvKey(field reflect.StructField, key string) string {
+	if envKey := field.Tag.Get("env"); envKey != "" {
+		return envKey
+	}
+	return l.buildKey(key)
+}
+
+// loadMap builds a map for a map[string]string field. File values nested under
+// key (e.g. FLAGS.X and FLAGS.Y for key FLAGS) become entries with the key prefix
+// removed; sub-keys are lower-cased unless the loader is case-sensitive. Provider
+// entries override file entries with the same sub-key. If no such entries exist, the default tag is parsed as "k=v,k2=v2". An environment variable
+// in the same "k=v" form is applied on top, overriding individual entries.
+func (l *Loader) loadMap(key, envKey, defaultValue string) map[string]string {
+	m := make(map[string]string)
+
+	prefix := key + "."
+	for _, values := range []map[string]string{l.values, l.remote} {
+		for k, v := range values {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			sub := strings.TrimPrefix(k, prefix)
+			if !l.caseSensitive {
+				sub = strings.ToLower(sub)
+			}
+			m[sub] = v
+		}
+	}
+
+	if len(m) == 0 {
+		parsePairs(defaultValue, m)
+	}
+	if envVal, _ := l.lookupEnv(key, envKey); envVal != "" {
+		parsePairs(envVal, m)
+	}
+	return m
+}
+
+// parsePairs parses comma-separated key=value pairs into m.
+func parsePairs(s string, m map[string]string) {
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			m[k] = strings.TrimSpace(v)
+		}
+	}
+}
+
 func (l *Loader) setField(field reflect.Value, value string) error {
+	// Types that parse themselves, such as custom enums, net.IP, and time.Time
+	if u, ok := textUnmarshaler(field); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
 	switch field.Kind() {
 	case reflect.String:
 		field.SetString(value)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		// Note: time.Duration fields are handled separately in Load()
-		i, err := strconv.ParseInt(value, 10, 64)
+		i, err := parseInt(value, 64)
 		if err != nil {
 			return err
 		}
@@ -354,3 +1046,22 @@ func (l *Loader) setField(field reflect.Value, value string) error {
 
 	return nil
 }
+
+// textUnmarshaler returns field as an encoding.TextUnmarshaler if its type or
+// a pointer to it implements the interface. A nil pointer field is allocated.
+func textUnmarshaler(field reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if field.Kind() == reflect.Ptr && field.Type().Implements(textUnmarshalerType) {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		u, ok := field.Interface().(encoding.TextUnmarshaler)
+		return u, ok
+	}
+	if field.CanAddr() {
+		u, ok := field.Addr().Interface().(encoding.TextUnmarshaler)
+		return u, ok
+	}
+	return nil, false
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
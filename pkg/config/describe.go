@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// FieldDoc describes how a single struct field is configured.
+// It is intended for generating operator documentation or sample config files.
+type FieldDoc struct {
+	// Name is the Go struct field name, or the dotted path to a field of a
+	// nested struct, such as DB.Host.
+	Name string
+	// Key is the configuration key used in files.
+	Key string
+	// EnvVar is the environment variable that overrides the key.
+	EnvVar string
+	// Default is the value from the default tag, if any.
+	Default string
+	// Required reports whether the field is tagged required:"true".
+	Required bool
+	// Secret reports whether the field is tagged secret:"true" and its
+	// value should be redacted from logs and dumps.
+	Secret bool
+	// Type is the Go type of the field, such as "int" or "time.Duration".
+	Type string
+}
+
+// DescribeStruct reflects over a config struct (or pointer to one) and returns a
+// FieldDoc for each settable field. Environment variable names are reported
+// without a prefix; use Loader.DescribeStruct to apply one.
+func DescribeStruct(s interface{}) []FieldDoc {
+	return New("").DescribeStruct(s)
+}
+
+// DescribeStruct reflects over a config struct (or pointer to one) and returns a
+// FieldDoc for each settable field, using the loader's prefix for environment
+// variable names exactly as Load would. Nested structs are described field by
+// field, with dotted keys such as db.host and names such as DB.Host.
+func (l *Loader) DescribeStruct(s interface{}) []FieldDoc {
+	t := reflect.TypeOf(s)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return l.describeStruct(t, "", "", "", make([]FieldDoc, 0, t.NumField()))
+}
+
+// describeStruct appends a FieldDoc for each field of t to docs, recursing
+// into nested structs with the same prefixes as loadStruct.
+func (l *Loader) describeStruct(t reflect.Type, namePrefix, keyPrefix, envPrefix string, docs []FieldDoc) []FieldDoc {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, envKey := l.fieldKeys(field, keyPrefix, envPrefix)
+		if isNestedStruct(field.Type) {
+			docs = l.describeStruct(field.Type, namePrefix+field.Name+".", key+".", envKey+"_", docs)
+			continue
+		}
+
+		required, _ := strconv.ParseBool(field.Tag.Get("required"))
+		secret, _ := strconv.ParseBool(field.Tag.Get("secret"))
+		docs = append(docs, FieldDoc{
+			Name:     namePrefix + field.Name,
+			Key:      key,
+			EnvVar:   envKey,
+			Default:  field.Tag.Get("default"),
+			Required: required,
+			Secret:   secret,
+			Type:     field.Type.String(),
+		})
+	}
+	return docs
+}
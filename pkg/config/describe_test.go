@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribeStruct(t *testing.T) {
+	type TestConfig struct {
+		Port    int           `config:"port" default:"8080"`
+		APIKey  string        `config:"api_key" env:"SERVICE_API_KEY" required:"true" secret:"true"`
+		Timeout time.Duration `config:"timeout" default:"30s"`
+		Verbose bool
+		hidden  string
+	}
+
+	docs := New("APP").DescribeStruct(&TestConfig{})
+	if len(docs) != 4 {
+		t.Fatalf("expected 4 field docs, got %d", len(docs))
+	}
+
+	expected := []FieldDoc{
+		{Name: "Port", Key: "port", EnvVar: "APP_PORT", Default: "8080", Type: "int"},
+		{Name: "APIKey", Key: "api_key", EnvVar: "SERVICE_API_KEY", Required: true, Secret: true, Type: "string"},
+		{Name: "Timeout", Key: "timeout", EnvVar: "APP_TIMEOUT", Default: "30s", Type: "time.Duration"},
+		{Name: "Verbose", Key: "verbose", EnvVar: "APP_VERBOSE", Type: "bool"},
+	}
+	for i, want := range expected {
+		if docs[i] != want {
+			t.Errorf("field %d: expected %+v, got %+v", i, want, docs[i])
+		}
+	}
+
+	// The package-level helper does not apply a prefix
+	if docs := DescribeStruct(TestConfig{}); docs[0].EnvVar != "PORT" {
+		t.Errorf("expected unprefixed env var PORT, got %s", docs[0].EnvVar)
+	}
+}
+
+func TestDescribeStructNested(t *testing.T) {
+	type DB struct {
+		Host     string `config:"host" default:"localhost"`
+		Password string `config:"password" env:"DB_PASSWORD" secret:"true"`
+	}
+	type TestConfig struct {
+		Port int `config:"port"`
+		DB   DB  `config:"db"`
+	}
+
+	docs := New("APP").DescribeStruct(&TestConfig{})
+	expected := []FieldDoc{
+		{Name: "Port", Key: "port", EnvVar: "APP_PORT", Type: "int"},
+		{Name: "DB.Host", Key: "db.host", EnvVar: "APP_DB_HOST", Default: "localhost", Type: "string"},
+		{Name: "DB.Password", Key: "db.password", EnvVar: "DB_PASSWORD", Secret: true, Type: "string"},
+	}
+	if len(docs) != len(expected) {
+		t.Fatalf("expected %d field docs, got %+v", len(expected), docs)
+	}
+	for i, want := range expected {
+		if docs[i] != want {
+			t.Errorf("field %d: expected %+v, got %+v", i, want, docs[i])
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+// Diff returns each key whose resolved value differs between prev and next,
+// mapped to its previous and new values. It compares the same keys Save
+// writes: loaded file and provider values with environment overrides applied,
+// plus struct fields populated by Load, including their defaults. A key known
+// to only one loader has an empty value in the other. Values of fields tagged
+// secret:"true" are returned as is, so redact them before logging.
+//
+// Loaders reloaded by Reload or Watch record their own changes; see Changes.
+func Diff(prev, next *Loader) map[string][2]string {
+	prevValues, nextValues := prev.resolvedValues(), next.resolvedValues()
+	changes := make(map[string][2]string)
+	for key, old := range prevValues {
+		if val := nextValues[key]; val != old {
+			changes[key] = [2]string{old, val}
+		}
+	}
+	for key, val := range nextValues {
+		if _, ok := prevValues[key]; !ok && val != "" {
+			changes[key] = [2]string{"", val}
+		}
+	}
+	return changes
+}
+
+// resolvedValues returns AsMap with the resolved values of fields populated
+// by Load added.
+func (l *Loader) resolvedValues() map[string]string {
+	values := l.AsMap()
+	for key, info := range l.fields {
+		if val, _, found := l.resolve(key, info.envKey, info.defaultValue); found {
+			values[key] = val
+		}
+	}
+	return values
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	load := func(name, content string) *Loader {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		loader := New("")
+		if err := loader.LoadFile(path); err != nil {
+			t.Fatalf("LoadFile failed: %v", err)
+		}
+		return loader
+	}
+
+	prev := load("old.yaml", "host: db.internal\nport: 8080\ndebug: false\n")
+	next := load("new.yaml", "host: db.internal\nport: 9090\ndebug: false\n")
+
+	want := map[string][2]string{"PORT": {"8080", "9090"}}
+	if got := Diff(prev, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected only the changed key %v, got %v", want, got)
+	}
+	if got := Diff(prev, prev); len(got) != 0 {
+		t.Errorf("expected no changes for identical loaders, got %v", got)
+	}
+
+	added := load("added.yaml", "host: db.internal\nport: 8080\ndebug: false\nregion: eu\n")
+	if got := Diff(prev, added); !reflect.DeepEqual(got, map[string][2]string{"REGION": {"", "eu"}}) {
+		t.Errorf("expected an added key with an empty old value, got %v", got)
+	}
+}
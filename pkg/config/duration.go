@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// extendedUnits are the duration units accepted in addition to those of
+// time.ParseDuration.
+var extendedUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseDuration parses s like time.ParseDuration, additionally accepting the
+// units "d" (24 hours) and "w" (7 days), alone or combined with standard units,
+// as in "7d", "2w", or "1w3d12h". Values that do not fit in a time.Duration
+// are rejected.
+func parseDuration(s string) (time.Duration, error) {
+	if !strings.ContainsAny(s, "dw") {
+		return time.ParseDuration(s)
+	}
+
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	var extended time.Duration
+	var standard strings.Builder
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		number := s[:i]
+		j := strings.IndexAny(s[i:], "0123456789.")
+		if j < 0 {
+			j = len(s) - i
+		}
+		unit := s[i : i+j]
+		s = s[i+j:]
+
+		scale, ok := extendedUnits[unit]
+		if !ok {
+			standard.WriteString(number + unit)
+			continue
+		}
+		d, err := scaleDuration(number, scale)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", orig, err)
+		}
+		if extended > math.MaxInt64-d {
+			return 0, fmt.Errorf("invalid duration %q: value out of range", orig)
+		}
+		extended += d
+	}
+
+	var rest time.Duration
+	if standard.Len() > 0 {
+		var err error
+		if rest, err = time.ParseDuration(standard.String()); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+	}
+	if extended > math.MaxInt64-rest {
+		return 0, fmt.Errorf("invalid duration %q: value out of range", orig)
+	}
+
+	total := extended + rest
+	if neg {
+		total = -total
+	}
+	return total, nil
+}
+
+// scaleDuration returns number multiplied by scale, rejecting results that
+// overflow a time.Duration.
+func scaleDuration(number string, scale time.Duration) (time.Duration, error) {
+	if !strings.Contains(number, ".") {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || n > int64(math.MaxInt64/scale) {
+			return 0, fmt.Errorf("value out of range")
+		}
+		return time.Duration(n) * scale, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+	d := f * float64(scale)
+	if d >= math.MaxInt64 {
+		return 0, fmt.Errorf("value out of range")
+	}
+	return time.Duration(d), nil
+}
+
+// durationUnits are the units accepted by the unit struct tag.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// parseDurationUnit parses s as a duration for a field with the given unit
+// tag. With a unit, a plain number such as "10" or "1.5" is read in that unit,
+// so read_timeout_seconds: 10 with unit "s" is ten seconds; other values, and
+// all values without a unit, are parsed by parseDuration.
+func parseDurationUnit(s, unit string) (time.Duration, error) {
+	if unit == "" {
+		return parseDuration(s)
+	}
+	scale, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown duration unit %q", unit)
+	}
+
+	number := strings.TrimSpace(s)
+	neg := false
+	if number != "" && (number[0] == '-' || number[0] == '+') {
+		neg = number[0] == '-'
+		number = number[1:]
+	}
+	if number == "" || strings.Trim(number, "0123456789.") != "" {
+		return parseDuration(s)
+	}
+
+	d, err := scaleDuration(number, scale)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"7d", 7 * day},
+		{"2w", 14 * day},
+		{"1w3d", 10 * day},
+		{"1d12h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"-1d", -day},
+		{"90s", 90 * time.Second},
+		{"1h30m", 90 * time.Minute},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDuration(tt.input)
+			if err != nil {
+				t.Fatalf("expected %q to parse, got error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	for _, input := range []string{"d", "7x", "1w3", "7dd", "1.2.3d", "100000000w", "16000w", "15000w2000d", "1.5e9d"} {
+		if d, err := parseDuration(input); err == nil {
+			t.Errorf("expected error for %q, got %v", input, d)
+		}
+	}
+}
+
+func TestDurationDaysAndWeeks(t *testing.T) {
+	os.Setenv("TEST_RETENTION", "2w")
+	defer os.Unsetenv("TEST_RETENTION")
+
+	loader := New("TEST")
+	if got := loader.Duration("retention", time.Hour); got != 14*24*time.Hour {
+		t.Errorf("expected 2w to be 336h, got %v", got)
+	}
+
+	type TestConfig struct {
+		Retention time.Duration `config:"retention"`
+		Grace     time.Duration `config:"grace" default:"1d"`
+	}
+	var cfg TestConfig
+	if err := New("TEST").Load(&cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Retention != 14*24*time.Hour {
+		t.Errorf("expected retention 336h, got %v", cfg.Retention)
+	}
+	if cfg.Grace != 24*time.Hour {
+		t.Errorf("expected default grace 24h, got %v", cfg.Grace)
+	}
+}
+
+func TestLoadDurationUnitTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "read_timeout_seconds: 10\npoll_interval_ms: 1.5\nidle_timeout_seconds: 2m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type TestConfig struct {
+		ReadTimeout  time.Duration `config:"read_timeout_seconds" unit:"s"`
+		PollInterval time.Duration `config:"poll_interval_ms" unit:"ms"`
+		IdleTimeout  time.Duration `config:"idle_timeout_seconds" unit:"s"`
+		DrainTimeout time.Duration `config:"drain_timeout_minutes" unit:"m" default:"5"`
+	}
+
+	loader := New("TEST")
+	if err := loader.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	var cfg TestConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.ReadTimeout != 10*time.Second {
+		t.Errorf("expected read timeout 10s, got %v", cfg.ReadTimeout)
+	}
+	if cfg.PollInterval != 1500*time.Microsecond {
+		t.Errorf("expected poll interval 1.5ms, got %v", cfg.PollInterval)
+	}
+	if cfg.IdleTimeout != 2*time.Minute {
+		t.Errorf("expected string duration 2m to still parse, got %v", cfg.IdleTimeout)
+	}
+	if cfg.DrainTimeout != 5*time.Minute {
+		t.Errorf("expected default 5 in minutes, got %v", cfg.DrainTimeout)
+	}
+
+	type BadConfig struct {
+		Timeout time.Duration `config:"timeout" unit:"fortnight"`
+	}
+	if err := New("TEST").Load(&BadConfig{}); err == nil {
+		t.Error("expected an error for an unknown unit tag")
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+// stripJSONExtensions removes // and /* */ comments and trailing commas before
+// a closing } or ] from data, leaving string contents untouched, so that
+// commented JSON can be parsed by encoding/json. Comments are replaced with
+// whitespace that preserves line breaks, so syntax errors in the result still
+// point at the right line.
+func stripJSONExtensions(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	// pendingComma is the index in out of a comma that may turn out to be trailing
+	pendingComma := -1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			pendingComma = -1
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			end := min(i+1, len(data))
+			out = append(out, data[start:end]...)
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i < len(data) && !(data[i] == '*' && i+1 < len(data) && data[i+1] == '/') {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				}
+				i++
+			}
+			i++
+			out = append(out, ' ')
+
+		case c == ',':
+			pendingComma = len(out)
+			out = append(out, c)
+
+		case c == '}' || c == ']':
+			if pendingComma >= 0 {
+				out[pendingComma] = ' '
+				pendingComma = -1
+			}
+			out = append(out, c)
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			out = append(out, c)
+
+		default:
+			pendingComma = -1
+			out = append(out, c)
+		}
+	}
+	return out
+}
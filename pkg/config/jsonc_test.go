@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripJSONExtensions(t *testing.T) {
+	input := `{
+  // line comment
+  "url": "http://example.com/*not a comment*/", /* block
+  comment */ "list": [1, 2, 3,],
+  "quote": "say \"hi\" // still a string",
+}`
+	var got map[string]interface{}
+	if err := json.Unmarshal(stripJSONExtensions([]byte(input)), &got); err != nil {
+		t.Fatalf("expected stripped JSON to parse, got %v", err)
+	}
+	if got["url"] != "http://example.com/*not a comment*/" {
+		t.Errorf("expected comment markers inside strings to be kept, got %v", got["url"])
+	}
+	if got["quote"] != `say "hi" // still a string` {
+		t.Errorf("expected escaped quotes to be handled, got %v", got["quote"])
+	}
+	if list, ok := got["list"].([]interface{}); !ok || len(list) != 3 {
+		t.Errorf("expected a 3-element list, got %v", got["list"])
+	}
+}
+
+func TestLenientJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+  // Port the server listens on
+  "port": 9090,
+  /* Upstream services */
+  "hosts": ["a.internal", "b.internal",],
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New("TEST").LoadFile(path); err == nil {
+		t.Error("expected strict parsing to reject comments by default")
+	}
+
+	loader := New("TEST")
+	loader.LenientJSON(true)
+	if err := loader.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed in lenient mode: %v", err)
+	}
+	if port := loader.Int("port", 0); port != 9090 {
+		t.Errorf("expected port 9090, got %d", port)
+	}
+	if hosts := loader.String("hosts", ""); hosts != "a.internal,b.internal" {
+		t.Errorf("expected hosts a.internal,b.internal, got %q", hosts)
+	}
+}
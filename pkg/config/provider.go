@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Provider supplies configuration values from an external source such as
+// Consul, etcd, or AWS SSM. Keys use the same dotted form as flattened file
+// keys (e.g. "db.host") and are normalized like file keys.
+type Provider interface {
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// AddProvider registers a provider whose values are fetched on the next Load or
+// Refresh. Provider values resolve as the Remote source, which by default sits
+// below environment variables and above file values; use SetPriority to change
+// that. When several providers set the same key, the one added last wins.
+func (l *Loader) AddProvider(p Provider) {
+	l.providers = append(l.providers, p)
+}
+
+// Refresh fetches values from all registered providers, replacing the values
+// from any previous refresh. If a provider fails, the previous values are kept
+// and the error is returned.
+func (l *Loader) Refresh(ctx context.Context) error {
+	remote := make(map[string]string)
+	for i, p := range l.providers {
+		values, err := p.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("config provider %d failed: %w", i, err)
+		}
+		for k, v := range values {
+			remote[l.normalizeKey(k)] = v
+		}
+	}
+
+	l.remote = remote
+	// Cached durations may have been resolved from stale values
+	l.durations = make(map[string]time.Duration)
+	return nil
+}
+
+// fileProvider is a Provider that reads a configuration file on every Load.
+type fileProvider struct {
+	path string
+}
+
+// FileProvider returns a Provider that reads the file at path in any format
+// supported by LoadFile. Unlike LoadFile, the file is re-read on every Refresh,
+// and its values resolve as the Remote source.
+func FileProvider(path string) Provider {
+	return fileProvider{path: path}
+}
+
+// Load reads and flattens the file, preserving key casing.
+func (p fileProvider) Load(ctx context.Context) (map[string]string, error) {
+	tmp := New("")
+	tmp.CaseSensitive(true)
+	if err := tmp.LoadFile(p.path); err != nil {
+		return nil, err
+	}
+	return tmp.values, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memoryProvider is an in-memory Provider for tests.
+type memoryProvider struct {
+	values map[string]string
+	err    error
+}
+
+func (p *memoryProvider) Load(ctx context.Context) (map[string]string, error) {
+	return p.values, p.err
+}
+
+func TestProviderValuesResolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"db": {"host": "file.example.com", "name": "app"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	loader := New("APP")
+	if err := loader.LoadFile(configPath); err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+	loader.AddProvider(&memoryProvider{values: map[string]string{
+		"db.host": "remote.example.com",
+		"db.port": "5433",
+	}})
+
+	type Config struct {
+		Host string `config:"db.host"`
+		Port int    `config:"db.port" default:"5432"`
+		Name string `config:"db.name"`
+	}
+	var cfg Config
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.Host != "remote.example.com" {
+		t.Errorf("expected provider value to override file, got '%s'", cfg.Host)
+	}
+	if cfg.Port != 5433 {
+		t.Errorf("expected provider port 5433, got %d", cfg.Port)
+	}
+	if cfg.Name != "app" {
+		t.Errorf("expected file value 'app', got '%s'", cfg.Name)
+	}
+	if src := loader.Source("db.host"); src != "remote" {
+		t.Errorf("expected source 'remote', got '%s'", src)
+	}
+
+	// Environment variables still take precedence
+	os.Setenv("APP_DB.HOST", "env.example.com")
+	defer os.Unsetenv("APP_DB.HOST")
+	if host := loader.String("db.host", ""); host != "env.example.com" {
+		t.Errorf("expected env value to override provider, got '%s'", host)
+	}
+}
+
+func TestRefreshKeepsValuesOnError(t *testing.T) {
+	p := &memoryProvider{values: map[string]string{"region": "us-east-1"}}
+	loader := New("")
+	loader.AddProvider(p)
+
+	if err := loader.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	p.values = map[string]string{"region": "eu-west-1"}
+	p.err = errors.New("unavailable")
+	if err := loader.Refresh(context.Background()); err == nil {
+		t.Error("expected refresh error")
+	}
+	if region := loader.String("region", ""); region != "us-east-1" {
+		t.Errorf("expected previous value 'us-east-1', got '%s'", region)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("region: us-east-1\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	loader := New("")
+	loader.AddProvider(FileProvider(configPath))
+	if err := loader.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if region := loader.String("region", ""); region != "us-east-1" {
+		t.Errorf("expected 'us-east-1', got '%s'", region)
+	}
+
+	// The file is re-read on refresh
+	if err := os.WriteFile(configPath, []byte("region: eu-west-1\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite config file: %v", err)
+	}
+	if err := loader.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if region := loader.String("region", ""); region != "eu-west-1" {
+		t.Errorf("expected 'eu-west-1' after refresh, got '%s'", region)
+	}
+}
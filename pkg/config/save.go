@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SaveOptions configures Loader.Save.
+type SaveOptions struct {
+	// ExcludeSecrets omits fields tagged secret:"true" in a struct passed to Load.
+	ExcludeSecrets bool
+}
+
+// Save writes the currently resolved configuration to path. The format is chosen
+// by extension: .json, .yaml/.yml, or .env for KEY=value lines. The output contains
+// every loaded file value with environment overrides applied, plus the resolved
+// values (including tag defaults) of struct fields populated by Load.
+//
+// Keys are written flat, in dotted form for nested values (db.host), and lower-cased
+// unless the loader is case-sensitive, so the file can be read back with LoadFile.
+func (l *Loader) Save(path string, opts ...SaveOptions) error {
+	var o SaveOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	values := l.resolvedValues()
+	for key, info := range l.fields {
+		if o.ExcludeSecrets && info.secret {
+			delete(values, key)
+		}
+	}
+
+	out := make(map[string]string, len(values))
+	for key, val := range values {
+		if !l.caseSensitive {
+			key = strings.ToLower(key)
+		}
+		out[key] = val
+	}
+
+	var data []byte
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		data, err = json.MarshalIndent(out, "", "  ")
+		data = append(data, '\n')
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(out)
+	case ".env":
+		data = marshalKeyValue(out, !l.caseSensitive)
+	default:
+		return fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// marshalKeyValue renders values as sorted KEY=value lines, quoting values
+// that contain whitespace or a comment character. Keys are upper-cased
+// when upper is set, following the usual .env convention.
+func marshalKeyValue(values map[string]string, upper bool) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		val := values[key]
+		if strings.ContainsAny(val, " \t#") {
+			val = `"` + val + `"`
+		}
+		if upper {
+			key = strings.ToUpper(key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, val)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	yamlData := `host: yaml.example.com
+database:
+  name: app
+`
+
+	if err := os.WriteFile(configPath, []byte(yamlData), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	type TestConfig struct {
+		Host    string `config:"host" default:"localhost"`
+		Port    int    `config:"port" default:"8080"`
+		Token   string `config:"token" default:"secret-token" secret:"true"`
+		Message string `config:"message" default:"hello world"`
+	}
+
+	loader := New("")
+	if err := loader.LoadFile(configPath); err != nil {
+		t.Fatalf("failed to load YAML file: %v", err)
+	}
+	var cfg TestConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	for _, name := range []string{"saved.json", "saved.yaml", "saved.env"} {
+		t.Run(name, func(t *testing.T) {
+			savedPath := filepath.Join(tmpDir, name)
+			if err := loader.Save(savedPath); err != nil {
+				t.Fatalf("failed to save config: %v", err)
+			}
+
+			reloaded := New("")
+			if err := reloaded.LoadFile(savedPath); err != nil {
+				t.Fatalf("failed to reload saved config: %v", err)
+			}
+
+			expected := map[string]string{
+				"HOST":          "yaml.example.com",
+				"DATABASE.NAME": "app",
+				"PORT":          "8080",
+				"TOKEN":         "secret-token",
+				"MESSAGE":       "hello world",
+			}
+			got := reloaded.AsMap()
+			if len(got) != len(expected) {
+				t.Errorf("expected %d keys, got %v", len(expected), got)
+			}
+			for key, want := range expected {
+				if got[key] != want {
+					t.Errorf("expected %s=%q, got %q", key, want, got[key])
+				}
+			}
+		})
+	}
+}
+
+func TestSaveExcludeSecrets(t *testing.T) {
+	type TestConfig struct {
+		Host  string `config:"host" default:"localhost"`
+		Token string `config:"token" default:"secret-token" secret:"true"`
+	}
+
+	loader := New("")
+	var cfg TestConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	savedPath := filepath.Join(t.TempDir(), "saved.json")
+	if err := loader.Save(savedPath, SaveOptions{ExcludeSecrets: true}); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	data, err := os.ReadFile(savedPath)
+	if err != nil {
+		t.Fatalf("failed to read saved config: %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("expected secret to be excluded, got: %s", data)
+	}
+	if !strings.Contains(string(data), "localhost") {
+		t.Errorf("expected non-secret value to be saved, got: %s", data)
+	}
+}
+
+func TestSaveUnsupportedExtension(t *testing.T) {
+	if err := New("").Save(filepath.Join(t.TempDir(), "config.toml")); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
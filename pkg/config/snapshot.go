@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot holds a configuration struct that can be replaced atomically.
+// Readers call Get for a lock-free, consistent view; a reload builds a
+// complete new value and swaps it in, so readers never see a partially
+// updated struct. The zero value is ready to use; Get returns nil until
+// the first successful Reload.
+type Snapshot[T any] struct {
+	ptr atomic.Pointer[T]
+}
+
+// NewSnapshot creates a snapshot populated from l.
+func NewSnapshot[T any](l *Loader) (*Snapshot[T], error) {
+	s := &Snapshot[T]{}
+	if err := s.Reload(l); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the current configuration. The returned value must be
+// treated as read-only, since other goroutines may hold the same pointer.
+func (s *Snapshot[T]) Get() *T {
+	return s.ptr.Load()
+}
+
+// Reload loads a fresh T from l with Load and swaps it in. On error the
+// current value is kept.
+func (s *Snapshot[T]) Reload(l *Loader) error {
+	next := new(T)
+	if err := l.Load(next); err != nil {
+		return err
+	}
+	s.ptr.Store(next)
+	return nil
+}
+
+// Watch runs l.Watch, reloading the snapshot whenever the loader's files
+// change. Errors from reading the files or loading the struct are passed to
+// onError if it is non-nil, and the previous snapshot stays in place.
+// Watch blocks until ctx is done and returns ctx.Err().
+func (s *Snapshot[T]) Watch(ctx context.Context, l *Loader, interval time.Duration, onError func(error)) error {
+	return l.Watch(ctx, interval, func(err error) {
+		if err == nil {
+			err = s.Reload(l)
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type pairConfig struct {
+	A int `config:"a"`
+	B int `config:"b"`
+}
+
+func writePair(t *testing.T, path string, n int) {
+	t.Helper()
+	data := fmt.Sprintf(`{"a": %d, "b": %d}`, n, n)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestSnapshotConcurrentReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writePair(t, configPath, 0)
+
+	loader := New("")
+	if err := loader.LoadFile(configPath); err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+	snap, err := NewSnapshot[pairConfig](loader)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cfg := snap.Get()
+				if cfg.A != cfg.B {
+					t.Errorf("torn snapshot: a=%d b=%d", cfg.A, cfg.B)
+					return
+				}
+			}
+		}()
+	}
+
+	for n := 1; n <= 50; n++ {
+		writePair(t, configPath, n)
+		if err := loader.reloadFiles(); err != nil {
+			t.Fatalf("reload failed: %v", err)
+		}
+		if err := snap.Reload(loader); err != nil {
+			t.Fatalf("snapshot reload failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if cfg := snap.Get(); cfg.A != 50 || cfg.B != 50 {
+		t.Errorf("expected final snapshot a=50 b=50, got a=%d b=%d", cfg.A, cfg.B)
+	}
+}
+
+func TestSnapshotWatch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writePair(t, configPath, 1)
+
+	loader := New("")
+	if err := loader.LoadFile(configPath); err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+	snap, err := NewSnapshot[pairConfig](loader)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- snap.Watch(ctx, loader, 10*time.Millisecond, func(err error) {
+			// A reload can race with the write and see a partial file
+			t.Logf("watch error: %v", err)
+		})
+	}()
+
+	// The watcher may start after the first write, so keep moving the
+	// modification time forward until the change is picked up
+	writePair(t, configPath, 2)
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 1; snap.Get().A != 2; i++ {
+		if time.Now().After(deadline) {
+			t.Fatal("snapshot was not reloaded after the file changed")
+		}
+		later := time.Now().Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(configPath, later, later); err != nil {
+			t.Fatalf("failed to update modification time: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateFile checks that the configuration file at path is valid for the
+// struct type of structType (a struct or pointer to one, which is not
+// modified). Only the file and default tags are consulted; environment
+// variables are ignored, so the check reflects what the file provides on its
+// own. It is intended for a pre-deploy command such as `myapp config validate`.
+//
+// Each exported field, including those of nested structs under dotted keys
+// such as db.host, is checked for:
+//   - a value when tagged required:"true"
+//   - a value that parses as the field's type
+//   - the rules in its validate tag, a comma-separated list of
+//     min=N, max=N (the value for numbers and durations, the length for
+//     strings and lists) and oneof=a|b|c
+//
+// All problems are reported together in the returned error.
+func ValidateFile(path string, structType interface{}) error {
+	t := reflect.TypeOf(structType)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a struct or pointer to a struct")
+	}
+
+	l := New("")
+	l.SetPriority([]Source{File, Default})
+	if err := l.LoadFile(path); err != nil {
+		return err
+	}
+
+	var problems []error
+	l.validateStruct(reflect.New(t).Elem(), "", &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("config file %s is invalid:\n%w", path, errors.Join(problems...))
+	}
+	return nil
+}
+
+// validateStruct checks the fields of struct value target against the loaded
+// file, recursing into nested structs under keyPrefix as loadStruct does.
+func (l *Loader) validateStruct(target reflect.Value, keyPrefix string, problems *[]error) {
+	t := target.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _ := l.fieldKeys(field, keyPrefix, "")
+		if isNestedStruct(field.Type) {
+			l.validateStruct(target.Field(i), name+".", problems)
+			continue
+		}
+
+		defaultValue, _ := l.fieldDefault(field)
+		value, _, found := l.resolve(l.normalizeKey(name), "", defaultValue)
+		if !found {
+			if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+				*problems = append(*problems, fmt.Errorf("%s: required but not set", name))
+			}
+			continue
+		}
+
+		fieldValue := target.Field(i)
+		if err := l.setValidatedField(field, fieldValue, value); err != nil {
+			*problems = append(*problems, fmt.Errorf("%s: invalid value %q: %w", name, value, err))
+			continue
+		}
+		if err := checkRules(field, fieldValue, value); err != nil {
+			*problems = append(*problems, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+}
+
+// setValidatedField parses value into fieldValue the same way Load would.
+func (l *Loader) setValidatedField(field reflect.StructField, fieldValue reflect.Value, value string) error {
+	switch fieldValue.Type() {
+	case reflect.TypeOf(time.Duration(0)):
+		d, err := parseDurationUnit(value, field.Tag.Get("unit"))
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(d))
+		return nil
+	case reflect.TypeOf([]string(nil)):
+		separators := field.Tag.Get("sep")
+		if separators == "" {
+			separators = DefaultSeparators
+		}
+		fieldValue.Set(reflect.ValueOf(splitList(value, separators)))
+		return nil
+	case reflect.TypeOf(map[string]string(nil)):
+		// Map fields are assembled from nested keys and have no scalar form
+		return nil
+	}
+	return l.setField(fieldValue, value)
+}
+
+// checkRules applies the field's validate tag to its parsed value.
+func checkRules(field reflect.StructField, fieldValue reflect.Value, raw string) error {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return nil
+	}
+
+	var problems []error
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "min", "max":
+			actual, limit, err := ruleOperands(fieldValue, arg)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("invalid %s rule: %w", name, err))
+				continue
+			}
+			if name == "min" && actual < limit {
+				problems = append(problems, fmt.Errorf("must be at least %s, got %s", arg, raw))
+			}
+			if name == "max" && actual > limit {
+				problems = append(problems, fmt.Errorf("must be at most %s, got %s", arg, raw))
+			}
+		case "oneof":
+			options := strings.Split(arg, "|")
+			if !slices.Contains(options, raw) {
+				problems = append(problems, fmt.Errorf("must be one of %s, got %s", strings.Join(options, ", "), raw))
+			}
+		default:
+			problems = append(problems, fmt.Errorf("unknown validate rule %q", name))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// ruleOperands returns the quantity a min or max rule compares and the parsed limit.
+func ruleOperands(v reflect.Value, arg string) (actual, limit float64, err error) {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := parseDuration(arg)
+		return float64(v.Int()), float64(d), err
+	}
+
+	limit, err = strconv.ParseFloat(arg, 64)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map:
+		actual = float64(v.Len())
+	default:
+		return 0, 0, fmt.Errorf("not supported for %s", v.Type())
+	}
+	return actual, limit, err
+}
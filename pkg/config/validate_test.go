@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validatedConfig struct {
+	Host     string        `config:"host" required:"true"`
+	Port     int           `config:"port" default:"8080" validate:"min=1,max=65535"`
+	Mode     string        `config:"mode" default:"release" validate:"oneof=debug|release"`
+	Timeout  time.Duration `config:"timeout" default:"30s" validate:"max=5m"`
+	Replicas int           `config:"replicas"`
+}
+
+func writeValidateFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestValidateFileValid(t *testing.T) {
+	path := writeValidateFile(t, "host: db.example.com\nport: 5432\n")
+
+	if err := ValidateFile(path, validatedConfig{}); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+}
+
+func TestValidateFileReportsAllProblems(t *testing.T) {
+	path := writeValidateFile(t, "port: 70000\nmode: verbose\ntimeout: 1h\nreplicas: three\n")
+
+	// Environment variables must not hide problems in the file
+	os.Setenv("HOST", "from-env")
+	defer os.Unsetenv("HOST")
+
+	err := ValidateFile(path, &validatedConfig{})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{
+		"host: required but not set",
+		"port: must be at most 65535",
+		"mode: must be one of debug, release",
+		"timeout: must be at most 5m",
+		`replicas: invalid value "three"`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+}
+
+func TestValidateFileNested(t *testing.T) {
+	type DB struct {
+		Host string `config:"host" required:"true"`
+		Port int    `config:"port" default:"5" validate:"max=10"`
+	}
+	type Config struct {
+		DB DB `config:"db"`
+	}
+
+	if err := ValidateFile(writeValidateFile(t, "db:\n  host: primary\n"), Config{}); err != nil {
+		t.Errorf("expected valid nested config, got %v", err)
+	}
+
+	err := ValidateFile(writeValidateFile(t, "db:\n  port: 11\n"), Config{})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"db.host: required but not set", "db.port: must be at most 10"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%s", want, err)
+		}
+	}
+}
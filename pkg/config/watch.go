@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// Watch polls the files loaded with LoadFile and the directories loaded with
+// LoadDownwardAPI every interval. When any of them changes, all of them are
+// re-read in their original order, replacing the previous file values, and
+// onChange is called with nil. If a file cannot be
+// loaded, the previous values are kept and onChange receives the error.
+// Watch blocks until ctx is done and returns ctx.Err().
+//
+// A Loader is not safe for concurrent use, so while Watch runs, read
+// configuration from onChange or through a Snapshot rather than calling the
+// Loader from other goroutines.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration, onChange func(error)) error {
+	modTimes := l.fileModTimes()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := l.fileModTimes()
+		if maps.EqualFunc(current, modTimes, time.Time.Equal) {
+			continue
+		}
+		modTimes = current
+		onChange(l.Reload())
+	}
+}
+
+// Reload re-reads the files loaded with LoadFile and the directories loaded
+// with LoadDownwardAPI in their original order, replacing the previous file
+// values, so a key removed from a file falls back to its next source. If a
+// file cannot be read, the previous values are kept and the error is returned.
+// Watch calls it whenever a file changes; call it directly to reload on demand,
+// such as on SIGHUP. Load the target struct again afterwards.
+func (l *Loader) Reload() error {
+	// reloadFiles replaces the values map, so the copy keeps the old values
+	prev := *l
+	err := l.reloadFiles()
+	l.changes = nil
+	if err == nil {
+		l.changes = Diff(&prev, l)
+	}
+	return err
+}
+
+// Changes returns the keys changed by the latest successful Reload or Watch reload,
+// mapped to their old and new values as reported by Diff. Call it from the
+// onChange callback to log exactly what changed:
+//
+//	cfg.Watch(ctx, 5*time.Second, func(err error) {
+//	    if err != nil {
+//	        log.Errorf("config reload failed: %v", err)
+//	        return
+//	    }
+//	    for key, change := range cfg.Changes() {
+//	        log.Infof("config %s changed from %q to %q", key, change[0], change[1])
+//	    }
+//	})
+//
+// It returns nil before the first reload and after a failed one. Like Diff, it
+// includes the values of secret fields as is.
+func (l *Loader) Changes() map[string][2]string {
+	return l.changes
+}
+
+// fileModTimes returns the modification time of each loaded file.
+// Files that cannot be read are recorded with the zero time.
+func (l *Loader) fileModTimes() map[string]time.Time {
+	times := make(map[string]time.Time, len(l.files))
+	for _, path := range l.files {
+		if info, err := os.Stat(path); err == nil {
+			times[path] = info.ModTime()
+		} else {
+			times[path] = time.Time{}
+		}
+	}
+	return times
+}
+
+// reloadFiles re-reads all loaded files and downward API directories into a
+// fresh values map. On error the previous values are restored.
+func (l *Loader) reloadFiles() error {
+	prev := l.values
+	l.values = make(map[string]string, len(prev))
+	for _, path := range l.files {
+		read := l.readFile
+		if slices.Contains(l.downwardDirs, path) {
+			read = l.readDownwardAPI
+		}
+		if err := read(path); err != nil {
+			l.values = prev
+			return fmt.Errorf("failed to reload %s: %w", path, err)
+		}
+	}
+	// Cached durations may have been resolved from stale values
+	l.durations = make(map[string]time.Duration)
+	return nil
+}
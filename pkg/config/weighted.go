@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WeightedEntry is a value with a relative weight, such as an upstream host
+// for weighted round-robin.
+type WeightedEntry struct {
+	Value  string
+	Weight int
+}
+
+// WeightedList loads a comma-separated list of value=weight pairs, such as
+// "host1:8080=3,host2:8080=1", in the order written. An entry without a weight
+// has weight 1. Weights must be positive integers; an invalid weight or an
+// empty value returns an error naming the entry. Returns nil if key is unset.
+func (l *Loader) WeightedList(key string) ([]WeightedEntry, error) {
+	var entries []WeightedEntry
+	for _, item := range splitList(l.String(key, ""), ",") {
+		value, weight, hasWeight := strings.Cut(item, "=")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("invalid weighted entry %q for %s: missing value", item, key)
+		}
+		entry := WeightedEntry{Value: value, Weight: 1}
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weighted entry %q for %s: weight must be a positive integer", item, key)
+			}
+			entry.Weight = w
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWeightedList(t *testing.T) {
+	t.Setenv("UPSTREAMS", "a=3, b=1,c")
+
+	loader := New("")
+	entries, err := loader.WeightedList("upstreams")
+	if err != nil {
+		t.Fatalf("WeightedList failed: %v", err)
+	}
+	want := []WeightedEntry{{Value: "a", Weight: 3}, {Value: "b", Weight: 1}, {Value: "c", Weight: 1}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("expected %v, got %v", want, entries)
+	}
+
+	if entries, err := loader.WeightedList("missing"); err != nil || entries != nil {
+		t.Errorf("expected nil entries for an unset key, got %v, %v", entries, err)
+	}
+
+	for _, bad := range []string{"a=x,b=1", "a=0", "a=-2", "=3"} {
+		t.Setenv("UPSTREAMS", bad)
+		if _, err := loader.WeightedList("upstreams"); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// contextKey is the type of the context key used to store a Logger.
+type contextKey struct{}
+
+// nop is the shared logger returned by FromContext when none is stored.
+var nop = Nop()
+
+// Nop returns a logger that discards every entry.
+func Nop() *Logger {
+	return NewWithHandler(slog.DiscardHandler)
+}
+
+// IntoContext returns a copy of ctx that carries l. Retrieve it with FromContext
+// anywhere further down the call stack instead of threading the logger through
+// every function signature.
+func IntoContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext, or a logger that
+// discards everything if none is stored. It never returns nil.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return nop
+}
+
+// ContextExtractor returns fields carried by ctx that belong on every entry
+// logged with it, such as trace IDs. It returns nil if ctx carries none.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// RegisterContextExtractor adds fn to the extractors consulted by the
+// *Context logging methods, such as InfoContext. Register extractors during
+// program initialization; fields from later extractors replace those from
+// earlier ones with the same key.
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// contextFields returns the fields found in ctx by the registered extractors.
+func contextFields(ctx context.Context) map[string]interface{} {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	var fields map[string]interface{}
+	for _, fn := range extractors {
+		for k, v := range fn(ctx) {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// DebugContext logs a message at DebugLevel with fields extracted from ctx
+// by the registered ContextExtractors.
+func (l *Logger) DebugContext(ctx context.Context, msg string) {
+	l.logFields(ctx, slog.LevelDebug, msg, contextFields(ctx))
+}
+
+// InfoContext logs a message at InfoLevel with fields extracted from ctx
+// by the registered ContextExtractors.
+func (l *Logger) InfoContext(ctx context.Context, msg string) {
+	l.logFields(ctx, slog.LevelInfo, msg, contextFields(ctx))
+}
+
+// WarnContext logs a message at WarnLevel with fields extracted from ctx
+// by the registered ContextExtractors.
+func (l *Logger) WarnContext(ctx context.Context, msg string) {
+	l.logFields(ctx, slog.LevelWarn, msg, contextFields(ctx))
+}
+
+// ErrorContext logs a message at ErrorLevel with fields extracted from ctx
+// by the registered ContextExtractors.
+func (l *Logger) ErrorContext(ctx context.Context, msg string) {
+	l.logFields(ctx, slog.LevelError, msg, contextFields(ctx))
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewWithHandler(slog.NewTextHandler(buf, nil)).WithField("request_id", "abc")
+
+	ctx := IntoContext(context.Background(), log)
+	if got := FromContext(ctx); got != log {
+		t.Fatalf("expected the stored logger, got %v", got)
+	}
+
+	FromContext(ctx).Info("handled")
+	if out := buf.String(); !strings.Contains(out, "request_id=abc") {
+		t.Errorf("expected stored logger's fields in output, got %q", out)
+	}
+}
+
+func TestFromContextNop(t *testing.T) {
+	log := FromContext(context.Background())
+	if log == nil {
+		t.Fatal("expected a nop logger, got nil")
+	}
+
+	// Must be safe to use without panicking
+	log.WithField("key", "value").Info("discarded")
+	log.Errorf("discarded %d", 1)
+
+	if got := FromContext(IntoContext(context.Background(), nil)); got == nil {
+		t.Error("expected a nop logger for a nil stored logger, got nil")
+	}
+}
@@ -20,6 +20,21 @@
 //
 // Only messages at or above the configured level will be logged.
 //
+// ParseLevel converts a name such as "debug" or "WARN" to a Level and
+// returns an error for unknown names:
+//
+//	level, err := logger.ParseLevel(os.Getenv("LOG_LEVEL"))
+//
+// Log takes the level as an argument, for levels computed at runtime, along
+// with fields for that entry only:
+//
+//	log.Log(level, "request completed", map[string]interface{}{"status": status})
+//
+// SetLevel changes the level at runtime, for the logger and every logger
+// derived from it:
+//
+//	log.SetLevel(logger.DebugLevel)
+//
 // # Contextual Logging
 //
 // Add contextual fields to log messages:
@@ -30,6 +45,50 @@
 //	    "ip": "192.168.1.1",
 //	}).Info("User logged in")
 //
+// Deriving a logger with WithField is cheap: fields are kept in a small slice
+// and only formatted when an entry is written, so per-request loggers add
+// little overhead on hot paths.
+//
+// Group related fields under a namespace with WithGroup:
+//
+//	log.WithGroup("db").WithField("host", host).Info("Connected")
+//	// text: db.host=localhost, JSON: {"db":{"host":"localhost"}}
+//
+// Attach a service name to every entry with WithService. Unlike WithField,
+// the service field cannot be overwritten by later fields:
+//
+//	log = log.WithService("billing")
+//
+// RedactFields hides the values of sensitive fields, matching keys
+// case-insensitively, however the fields were added:
+//
+//	log.RedactFields("password", "ssn", "token")
+//	log.WithField("password", pw).Info("login") // password=[REDACTED]
+//
+// Clone returns a detached copy whose output can be changed without affecting
+// the original:
+//
+//	auditLog := log.Clone()
+//	auditLog.SetOutput(auditFile)
+//
+// # Context
+//
+// Store a request-scoped logger in a context with IntoContext and retrieve it
+// anywhere down the call stack with FromContext, which returns a logger that
+// discards everything when none is stored:
+//
+//	ctx = logger.IntoContext(ctx, log.WithField("request_id", id))
+//	logger.FromContext(ctx).Info("charging card")
+//
+// Values carried by a context, such as trace IDs, can be added to entries
+// automatically. Register a ContextExtractor at startup and log with the
+// *Context methods (DebugContext, InfoContext, WarnContext, ErrorContext).
+// The otellogger subpackage provides one for OpenTelemetry, adding trace_id
+// and span_id while keeping the dependency out of this package:
+//
+//	logger.RegisterContextExtractor(otellogger.Extractor)
+//	log.InfoContext(ctx, "charging card")
+//
 // # Formatted Logging
 //
 // All levels support formatted messages:
@@ -37,6 +96,47 @@
 //	log.Infof("Processing %d items", count)
 //	log.Errorf("Connection failed: %v", err)
 //
+// # Output Failures
+//
+// If writing to the output fails (for example, a full disk), entries are
+// written to a fallback writer instead, with a one-time warning. The fallback
+// defaults to os.Stderr:
+//
+//	log.SetFallbackOutput(os.Stdout)
+//
+// # Separate Error Output
+//
+// SetErrorOutput sends Warn and Error entries to their own writer, leaving
+// Debug and Info entries on the main output:
+//
+//	log.SetOutput(appLog)
+//	log.SetErrorOutput(errorLog)
+//
+// Close closes file outputs when the program exits, so buffered data reaches
+// disk; os.Stdout and os.Stderr are left open:
+//
+//	defer log.Close()
+//
+// # Standard Library Interop
+//
+// Writer adapts the logger to an io.Writer, logging each line at a fixed level.
+// This is useful for http.Server.ErrorLog and other *log.Logger consumers:
+//
+//	errorLog := stdlog.New(log.Writer(logger.ErrorLevel), "", 0)
+//
+// LogrLogger adapts the logger to go-logr's logr.Logger for Kubernetes-style
+// libraries. V(0) logs at Info and higher V-levels at Debug; errors log at
+// Error with an error field:
+//
+//	ctrl.SetLogger(log.LogrLogger())
+//
+// # Syslog
+//
+// On Unix systems, logs can be shipped to a syslog daemon. Each entry is sent
+// with the syslog severity matching its level:
+//
+//	log, err := logger.NewSyslog(logger.InfoLevel, "udp", "logs.internal:514", "myapp")
+//
 // # Using slog Directly
 //
 // For advanced use cases, you can create a logger with a custom slog.Handler:
@@ -50,4 +150,13 @@
 //
 // By default, log messages use slog's text format:
 //   time=2025-10-22T16:00:00.000Z level=INFO msg="message" field1=value1 field2=value2
+//
+// SetFormat(LogfmtFormat) switches to logfmt, which log stores such as Loki
+// parse directly. Entries start with time, level, and msg, followed by fields
+// sorted by key. Values containing spaces, '=', or quotes are quoted and
+// escaped:
+//
+//	log.SetFormat(logger.LogfmtFormat)
+//	log.WithFields(map[string]interface{}{"user": "alice", "note": `say "hi"`}).Info("login ok")
+//	// time=2025-10-22T16:00:00Z level=INFO msg="login ok" note="say \"hi\"" user=alice
 package logger
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// NewLogfmtHandler returns a slog.Handler that writes logfmt lines to w, as
+// ingested by tools such as Grafana Loki. Each line starts with time, level,
+// and msg, followed by the entry's fields sorted by key. Keys inside groups
+// are joined with dots, as in db.host=primary. Values containing spaces, '=',
+// quotes, or control characters are quoted with Go escaping. opts may be nil;
+// only its Level is used.
+func NewLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	h := &logfmtHandler{w: w, mu: &sync.Mutex{}}
+	if opts != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+// logfmtHandler is the slog.Handler returned by NewLogfmtHandler.
+type logfmtHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	level  slog.Leveler
+	fields []logfmtField // added with WithAttrs, keys already qualified
+	prefix string        // open groups, each followed by "."
+}
+
+// logfmtField is a flattened attribute with its fully qualified key.
+type logfmtField struct {
+	key   string
+	value slog.Value
+}
+
+func (h *logfmtHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.level != nil {
+		min = h.level.Level()
+	}
+	return level >= min
+}
+
+func (h *logfmtHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := append([]logfmtField(nil), h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendLogfmtFields(fields, h.prefix, a)
+		return true
+	})
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+
+	var b strings.Builder
+	if !r.Time.IsZero() {
+		b.WriteString("time=" + r.Time.Format(time.RFC3339Nano) + " ")
+	}
+	b.WriteString("level=" + r.Level.String())
+	b.WriteString(" msg=" + logfmtQuote(r.Message))
+	for _, f := range fields {
+		b.WriteString(" " + logfmtKey(f.key) + "=" + logfmtQuote(logfmtValue(f.value)))
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	child.fields = append([]logfmtField(nil), h.fields...)
+	for _, a := range attrs {
+		child.fields = appendLogfmtFields(child.fields, h.prefix, a)
+	}
+	return &child
+}
+
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+// appendLogfmtFields appends a to fields under prefix, flattening groups into
+// dotted keys and dropping empty attributes as slog's handlers do.
+func appendLogfmtFields(fields []logfmtField, prefix string, a slog.Attr) []logfmtField {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendLogfmtFields(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+	return append(fields, logfmtField{key: prefix + a.Key, value: a.Value})
+}
+
+// logfmtValue renders v as a string before quoting.
+func logfmtValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return fmt.Sprint(v.Any())
+	default:
+		return v.String()
+	}
+}
+
+// logfmtQuote quotes s if it is empty or contains characters that would
+// break a logfmt pair, escaping quotes, backslashes, and control characters.
+func logfmtQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// logfmtKey replaces characters that are not allowed in a logfmt key with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogfmtFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetOutput(buf)
+	log.SetFormat(LogfmtFormat)
+
+	log.WithFields(map[string]interface{}{
+		"zone":  "us east",
+		"plain": "value",
+		"expr":  "a=b",
+		"quote": `say "hi"`,
+	}).Info("request done")
+
+	line := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(line, "time=") || !strings.Contains(line, ` level=INFO msg="request done" `) {
+		t.Errorf("expected time, level, and msg first, got: %s", line)
+	}
+	if !strings.HasSuffix(line, `expr="a=b" plain=value quote="say \"hi\"" zone="us east"`) {
+		t.Errorf("expected sorted fields with spaces, '=', and quotes quoted, got: %s", line)
+	}
+	if strings.Contains(line, `plain="value"`) {
+		t.Errorf("expected a value without spaces to be unquoted, got: %s", line)
+	}
+}
+
+func TestLogfmtHandlerGroups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewWithHandler(NewLogfmtHandler(buf, nil))
+
+	log.WithField("b", 1).WithGroup("db").WithField("host", "primary").Info("connected")
+	log.Debug("hidden")
+
+	line := strings.TrimSpace(buf.String())
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("expected only the Info entry, got: %s", buf.String())
+	}
+	if !strings.HasSuffix(line, "msg=connected b=1 db.host=primary") {
+		t.Errorf("expected grouped keys joined with dots, got: %s", line)
+	}
+}
@@ -3,10 +3,15 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // Level represents the severity of a log message.
@@ -23,94 +28,435 @@ const (
 	ErrorLevel
 )
 
+// ParseLevel converts a level name to a Level. It is case-insensitive and
+// accepts "debug", "info", "warn" (or "warning"), and "error". Unknown names
+// return an error rather than silently defaulting, so typos are caught.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("logger: unknown level %q", s)
+	}
+}
+
 // Logger provides structured logging capabilities using slog.
 type Logger struct {
 	logger *slog.Logger
+	// fields holds attributes added by WithField and WithFields since the last
+	// WithGroup or WithService. They are appended to each entry at log time,
+	// which keeps deriving a child logger cheap on hot paths.
+	fields []slog.Attr
+	// scope holds the groups and attributes applied to the handler by WithGroup
+	// and WithService, so it can be rebuilt with the same scope for new outputs.
+	scope   []scopeOp
+	level   *slog.LevelVar // shared with derived loggers, changed by SetLevel
+	out     *fallbackWriter // nil when using a custom handler
+	custom  slog.Handler    // handler passed to NewWithHandler, until replaced
+	closer  *onceCloser     // syslog connection or closable custom handler
+	errOut  *fallbackWriter // receives Warn and Error entries when set
+	redact  map[string]bool // lower-cased keys set with RedactFields
+	service string
+	format  Format
 }
 
+// Format selects how a Logger renders entries.
+type Format int
+
+const (
+	// TextFormat uses slog's text handler, keeping fields in the order added.
+	TextFormat Format = iota
+	// LogfmtFormat writes logfmt with time, level, and msg first and the
+	// remaining fields sorted by key; see NewLogfmtHandler.
+	LogfmtFormat
+)
+
+// scopeOp is a group or a set of attributes applied on top of the handler.
+type scopeOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+// apply returns h with the op applied.
+func (op scopeOp) apply(h slog.Handler) slog.Handler {
+	if op.group != "" {
+		return h.WithGroup(op.group)
+	}
+	if len(op.attrs) == 0 {
+		return h
+	}
+	return h.WithAttrs(op.attrs)
+}
+
+// ServiceKey is the field name used for the service set with WithService.
+const ServiceKey = "service"
+
 // New creates a new Logger with the specified minimum level using slog.
 // Logs with a level lower than the minimum will be discarded.
 func New(level Level) *Logger {
-	slogLevel := levelToSlogLevel(level)
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	slogLevel := newLevelVar(level)
+	out := newFallbackWriter(os.Stdout, os.Stderr)
+	handler := slog.NewTextHandler(out, &slog.HandlerOptions{
 		Level: slogLevel,
 	})
 	return &Logger{
 		logger: slog.New(handler),
+		level:  slogLevel,
+		out:    out,
 	}
 }
 
+// newLevelVar returns a slog.LevelVar set to level.
+func newLevelVar(level Level) *slog.LevelVar {
+	v := new(slog.LevelVar)
+	v.Set(levelToSlogLevel(level))
+	return v
+}
+
 // NewWithHandler creates a new Logger with a custom slog.Handler.
+// Write errors are the handler's responsibility; no fallback output is used.
+// If handler implements io.Closer, Close closes it.
 func NewWithHandler(handler slog.Handler) *Logger {
-	return &Logger{
+	l := &Logger{
 		logger: slog.New(handler),
+		level:  newLevelVar(InfoLevel),
+		custom: handler,
+	}
+	if c, ok := handler.(io.Closer); ok {
+		l.closer = &onceCloser{c: c}
 	}
+	return l
 }
 
-// SetOutput sets the output destination for the logger.
+// SetLevel changes the minimum level in place. It applies to l and to every
+// logger derived from it with WithField, WithFields, WithGroup, or
+// WithService, including ones created before the call, so a level can be
+// changed at runtime, such as on a configuration reload, without handing out a
+// new logger. A Clone keeps its own level. On a logger created with
+// NewWithHandler it has no effect on the custom handler, which does its own
+// filtering.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Set(levelToSlogLevel(level))
+}
+
+// SetOutput sets the output destination for the logger, keeping its level.
+// If writing to w fails, entries are written to the fallback output instead.
 func (l *Logger) SetOutput(w io.Writer) {
-	handler := slog.NewTextHandler(w, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})
+	l.out = newFallbackWriter(w, l.fallback())
+	l.rebuildHandler()
+}
+
+// SetErrorOutput routes Warn and Error entries to w, leaving Debug and Info
+// entries on the main output. If writing to w fails, entries are written to
+// the fallback output instead. On a logger created with NewWithHandler, Debug
+// and Info entries stay with the custom handler, while Warn and Error entries
+// are written to w in the logger's format, text by default.
+func (l *Logger) SetErrorOutput(w io.Writer) {
+	l.errOut = newFallbackWriter(w, l.fallback())
+	l.rebuildHandler()
+}
+
+// SetFormat sets how entries are rendered, keeping the logger's level and
+// outputs. On a logger created with NewWithHandler it replaces the custom
+// handler with one writing to os.Stdout.
+func (l *Logger) SetFormat(format Format) {
+	if l.out == nil {
+		l.out = newFallbackWriter(os.Stdout, os.Stderr)
+	}
+	l.format = format
+	l.rebuildHandler()
+}
+
+// fallback returns the current fallback output, defaulting to os.Stderr.
+func (l *Logger) fallback() io.Writer {
+	if l.out != nil {
+		return l.out.fallbackOutput()
+	}
+	return os.Stderr
+}
+
+// rebuildHandler replaces the slog handler with handlers in the configured
+// format writing to the configured outputs.
+func (l *Logger) rebuildHandler() {
+	opts := &slog.HandlerOptions{Level: l.level}
+	newHandler := func(w io.Writer) slog.Handler {
+		if l.format == LogfmtFormat {
+			return NewLogfmtHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
+	}
+	var handler slog.Handler
+	if l.out != nil {
+		handler = newHandler(l.out)
+	} else {
+		handler = l.custom
+	}
+	if l.errOut != nil {
+		handler = &splitHandler{
+			low:  handler,
+			high: newHandler(l.errOut),
+		}
+	}
+	if l.redact != nil {
+		handler = &redactHandler{next: handler, keys: l.redact}
+	}
+	for _, op := range l.scope {
+		handler = op.apply(handler)
+	}
 	l.logger = slog.New(handler)
 }
 
+// SetFallbackOutput sets where entries are written when the primary output
+// returns an error, such as when the disk holding a log file is full.
+// The default fallback is os.Stderr. It has no effect on loggers created
+// with NewWithHandler.
+func (l *Logger) SetFallbackOutput(w io.Writer) {
+	if l.out != nil {
+		l.out.setFallback(w)
+	}
+	if l.errOut != nil {
+		l.errOut.setFallback(w)
+	}
+}
+
+// Close closes the logger's output and error output if they are files or
+// other io.Closers, releasing their descriptors and flushing any buffered
+// data; os.Stdout and os.Stderr are left open. For a logger created with
+// NewSyslog it closes the connection to the syslog daemon. Loggers derived with WithField
+// or WithGroup share these outputs, so call Close once, when logging is done,
+// typically as the program exits; a Clone leaves them open. Entries logged afterwards go to the
+// fallback output. Calling Close again has no effect.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, w := range []*fallbackWriter{l.out, l.errOut} {
+		if w == nil {
+			continue
+		}
+		if err := w.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if l.closer != nil {
+		if err := l.closer.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// derive returns a logger sharing l's settings with a different slog.Logger.
+func (l *Logger) derive(logger *slog.Logger) *Logger {
+	return &Logger{
+		logger:  logger,
+		fields:  l.fields,
+		scope:   l.scope,
+		custom:  l.custom,
+		closer:  l.closer,
+		level:   l.level,
+		out:     l.out,
+		errOut:  l.errOut,
+		redact:  l.redact,
+		service: l.service,
+		format:  l.format,
+	}
+}
+
+// Clone returns a detached copy of l with the same fields, level, and output.
+// Changing the clone's level or output with SetLevel, SetOutput, SetErrorOutput,
+// or SetFallbackOutput does not affect l, which makes Clone useful for sending one
+// component's logs elsewhere. Closing the clone does not close the outputs it
+// shares with l, only those set on the clone afterwards.
+func (l *Logger) Clone() *Logger {
+	clone := l.derive(l.logger)
+	clone.closer = nil
+	clone.level = new(slog.LevelVar)
+	clone.level.Set(l.level.Level())
+	if l.out != nil {
+		clone.out = l.out.share()
+		if l.errOut != nil {
+			clone.errOut = l.errOut.share()
+		}
+		clone.rebuildHandler()
+	}
+	return clone
+}
+
+// WithService creates a new logger that attaches a persistent service field to
+// every entry it and its children produce. Unlike an ad-hoc field, the service
+// cannot be overwritten: WithField and WithFields ignore the "service" key on
+// loggers that have a service set. Call it on the root logger, before WithGroup,
+// so the field is not namespaced.
+func (l *Logger) WithService(name string) *Logger {
+	attrs := append(l.fields[:len(l.fields):len(l.fields)], slog.String(ServiceKey, name))
+	child := l.withScope(scopeOp{attrs: attrs})
+	child.service = name
+	return child
+}
+
 // WithField creates a new logger with an additional contextual field.
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return &Logger{
-		logger: l.logger.With(key, value),
+	if l.reserved(key) {
+		return l.derive(l.logger)
 	}
+	return l.withAttrs(slog.Any(key, value))
 }
 
 // WithFields creates a new logger with multiple contextual fields.
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	args := make([]any, 0, len(fields)*2)
+	attrs := make([]slog.Attr, 0, len(fields))
 	for k, v := range fields {
-		args = append(args, k, v)
+		if l.reserved(k) {
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, v))
 	}
-	return &Logger{
-		logger: l.logger.With(args...),
+	return l.withAttrs(attrs...)
+}
+
+// withAttrs returns a child logger with attrs appended to its pending fields.
+func (l *Logger) withAttrs(attrs ...slog.Attr) *Logger {
+	child := l.derive(l.logger)
+	// Clip the parent's slice so siblings never share appended elements
+	child.fields = append(l.fields[:len(l.fields):len(l.fields)], attrs...)
+	return child
+}
+
+// withScope returns a child logger with op applied to its handler. Pending
+// fields are expected to be part of op, so the child starts without any.
+func (l *Logger) withScope(op scopeOp) *Logger {
+	child := l.derive(slog.New(op.apply(l.logger.Handler())))
+	child.fields = nil
+	child.scope = append(l.scope[:len(l.scope):len(l.scope)], op)
+	return child
+}
+
+// log writes an entry at level with the pending fields appended.
+func (l *Logger) log(level slog.Level, msg string) {
+	l.logger.LogAttrs(context.Background(), level, msg, l.fields...)
+}
+
+// reserved reports whether key is protected from being set as an ad-hoc field.
+func (l *Logger) reserved(key string) bool {
+	return l.service != "" && key == ServiceKey
+}
+
+// WithGroup creates a new logger that namespaces subsequent fields under name.
+// With the text format keys are prefixed (db.host=...), while the JSON format
+// nests them ({"db":{"host":...}}). Groups compose when called repeatedly.
+func (l *Logger) WithGroup(name string) *Logger {
+	// Fields added so far belong outside the group
+	child := l.withScope(scopeOp{attrs: l.fields})
+	if name == "" {
+		return child
 	}
+	return child.withScope(scopeOp{group: name})
 }
 
 // Debug logs a message at DebugLevel.
 func (l *Logger) Debug(msg string) {
-	l.logger.Debug(msg)
+	l.log(slog.LevelDebug, msg)
 }
 
 // Debugf logs a formatted message at DebugLevel.
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	l.logger.Debug(sprintf(format, args...))
+	l.log(slog.LevelDebug, sprintf(format, args...))
 }
 
 // Info logs a message at InfoLevel.
 func (l *Logger) Info(msg string) {
-	l.logger.Info(msg)
+	l.log(slog.LevelInfo, msg)
 }
 
 // Infof logs a formatted message at InfoLevel.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.logger.Info(sprintf(format, args...))
+	l.log(slog.LevelInfo, sprintf(format, args...))
 }
 
 // Warn logs a message at WarnLevel.
 func (l *Logger) Warn(msg string) {
-	l.logger.Warn(msg)
+	l.log(slog.LevelWarn, msg)
 }
 
 // Warnf logs a formatted message at WarnLevel.
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.logger.Warn(sprintf(format, args...))
+	l.log(slog.LevelWarn, sprintf(format, args...))
 }
 
 // Error logs a message at ErrorLevel.
 func (l *Logger) Error(msg string) {
-	l.logger.Error(msg)
+	l.log(slog.LevelError, msg)
 }
 
 // Errorf logs a formatted message at ErrorLevel.
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.logger.Error(sprintf(format, args...))
+	l.log(slog.LevelError, sprintf(format, args...))
+}
+
+// Log logs msg at a level chosen at runtime, with fields added to this entry
+// only. A field with the same key as one of the logger's fields replaces it
+// for this entry; the logger itself is unchanged:
+//
+//	level := logger.InfoLevel
+//	if status >= 500 {
+//	    level = logger.ErrorLevel
+//	}
+//	log.Log(level, "request completed", map[string]interface{}{"status": status})
+func (l *Logger) Log(level Level, msg string, fields map[string]interface{}) {
+	l.logFields(context.Background(), levelToSlogLevel(level), msg, fields)
+}
+
+// logFields writes an entry with fields added after the logger's own fields,
+// replacing any with the same key.
+func (l *Logger) logFields(ctx context.Context, level slog.Level, msg string, fields map[string]interface{}) {
+	attrs := make([]slog.Attr, 0, len(l.fields)+len(fields))
+	for _, attr := range l.fields {
+		if _, ok := fields[attr.Key]; !ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !l.reserved(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, fields[k]))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// Writer returns an io.Writer that logs each line written to it at the given level.
+// It allows libraries that accept an io.Writer or *log.Logger to log through this logger:
+//
+//	errorLog := log.New(logger.Writer(logger.ErrorLevel), "", 0)
+func (l *Logger) Writer(level Level) io.Writer {
+	return &levelWriter{logger: l, level: levelToSlogLevel(level)}
+}
+
+// levelWriter adapts a Logger to io.Writer.
+type levelWriter struct {
+	logger *Logger
+	level  slog.Level
+}
+
+// Write splits p into lines and logs each non-empty line.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		w.logger.log(w.level, line)
+	}
+	return len(p), nil
 }
 
 // levelToSlogLevel converts our Level to slog.Level.
@@ -133,3 +479,106 @@ func levelToSlogLevel(level Level) slog.Level {
 func sprintf(format string, args ...interface{}) string {
 	return fmt.Sprintf(format, args...)
 }
+
+// fallbackWriter writes to a primary writer and switches to a fallback
+// writer for any write that fails, warning once about the failure.
+type fallbackWriter struct {
+	mu       sync.Mutex
+	primary  io.Writer
+	fallback io.Writer
+	warned   bool
+	closed   bool
+	shared   bool // primary belongs to another logger and is never closed
+}
+
+func newFallbackWriter(primary, fallback io.Writer) *fallbackWriter {
+	return &fallbackWriter{primary: primary, fallback: fallback}
+}
+
+// Write writes p to the primary writer, or to the fallback if that fails.
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.warned {
+		w.warned = true
+		fmt.Fprintf(w.fallback, "logger: primary output failed, falling back: %v\n", err)
+	}
+	return w.fallback.Write(p)
+}
+
+// close closes the primary writer once if it is an io.Closer other than
+// os.Stdout or os.Stderr.
+func (w *fallbackWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, ok := w.primary.(io.Closer)
+	if !ok || w.closed || w.shared || w.primary == os.Stdout || w.primary == os.Stderr {
+		return nil
+	}
+	w.closed = true
+	return c.Close()
+}
+
+// share returns a writer for the same primary and fallback with its own
+// fallback setting, which does not close the primary.
+func (w *fallbackWriter) share() *fallbackWriter {
+	return &fallbackWriter{primary: w.primary, fallback: w.fallbackOutput(), shared: true}
+}
+
+func (w *fallbackWriter) setFallback(fallback io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fallback = fallback
+}
+
+func (w *fallbackWriter) fallbackOutput() io.Writer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fallback
+}
+
+// onceCloser closes c the first time close is called.
+type onceCloser struct {
+	once sync.Once
+	c    io.Closer
+	err  error
+}
+
+func (o *onceCloser) close() error {
+	o.once.Do(func() { o.err = o.c.Close() })
+	return o.err
+}
+
+// splitHandler sends records at WarnLevel and above to high and all others
+// to low.
+type splitHandler struct {
+	low  slog.Handler
+	high slog.Handler
+}
+
+func (h *splitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= slog.LevelWarn {
+		return h.high.Enabled(ctx, level)
+	}
+	return h.low.Enabled(ctx, level)
+}
+
+func (h *splitHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		return h.high.Handle(ctx, r)
+	}
+	return h.low.Handle(ctx, r)
+}
+
+func (h *splitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &splitHandler{low: h.low.WithAttrs(attrs), high: h.high.WithAttrs(attrs)}
+}
+
+func (h *splitHandler) WithGroup(name string) slog.Handler {
+	return &splitHandler{low: h.low.WithGroup(name), high: h.high.WithGroup(name)}
+}
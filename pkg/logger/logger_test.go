@@ -2,7 +2,11 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
+	"os"
 	"strings"
 	"testing"
 )
@@ -114,3 +118,430 @@ func TestFormattedLogging(t *testing.T) {
 		t.Error("Should contain formatted message")
 	}
 }
+
+func TestWithGroupText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	log := NewWithHandler(handler)
+
+	log.WithGroup("db").WithField("host", "localhost").Info("connected")
+	if !strings.Contains(buf.String(), "db.host=localhost") {
+		t.Errorf("expected grouped field db.host, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	log.WithGroup("db").WithGroup("pool").WithField("size", 10).Info("configured")
+	if !strings.Contains(buf.String(), "db.pool.size=10") {
+		t.Errorf("expected nested group db.pool.size, got: %s", buf.String())
+	}
+}
+
+func TestWithGroupJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	log := NewWithHandler(handler)
+
+	log.WithGroup("db").WithField("host", "localhost").Info("connected")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	group, ok := entry["db"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested db object, got: %s", buf.String())
+	}
+	if group["host"] != "localhost" {
+		t.Errorf("expected db.host localhost, got %v", group["host"])
+	}
+}
+
+func TestWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	log := NewWithHandler(handler)
+
+	w := log.Writer(ErrorLevel)
+	if _, err := w.Write([]byte("first line\nsecond line\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log entries, got %d: %s", len(lines), buf.String())
+	}
+	for i, want := range []string{"first line", "second line"} {
+		if !strings.Contains(lines[i], "level=ERROR") {
+			t.Errorf("entry %d should be logged at ERROR: %s", i, lines[i])
+		}
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("entry %d should contain %q: %s", i, want, lines[i])
+		}
+	}
+}
+
+// failingWriter always returns an error, simulating a full disk.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestFallbackOutput(t *testing.T) {
+	fallback := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetFallbackOutput(fallback)
+	log.SetOutput(failingWriter{})
+
+	log.Info("first message")
+	log.WithField("key", "value").Info("second message")
+
+	output := fallback.String()
+	if !strings.Contains(output, "first message") || !strings.Contains(output, "second message") {
+		t.Errorf("expected messages in fallback output, got: %s", output)
+	}
+	if strings.Count(output, "primary output failed") != 1 {
+		t.Errorf("expected exactly one warning about the failing writer, got: %s", output)
+	}
+	if !strings.Contains(output, "no space left on device") {
+		t.Errorf("expected warning to include the write error, got: %s", output)
+	}
+}
+
+func TestClose(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "app-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetFallbackOutput(fallback)
+	log.SetOutput(f)
+	log.SetErrorOutput(os.Stderr)
+
+	log.Info("before close")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected the log file to be closed, got %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("expected a second Close to be a no-op, got %v", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before close") {
+		t.Errorf("expected entries written before Close in the file, got: %s", data)
+	}
+	log.Info("after close")
+	if !strings.Contains(fallback.String(), "after close") {
+		t.Errorf("expected entries after Close in the fallback output, got: %s", fallback.String())
+	}
+}
+
+func TestSetOutputKeepsLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(DebugLevel)
+	log.SetOutput(buf)
+
+	log.Debug("debug message")
+	if !strings.Contains(buf.String(), "debug message") {
+		t.Error("SetOutput should keep the logger's level")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetOutput(buf)
+	child := log.WithGroup("req").WithField("id", "42")
+	clone := log.Clone()
+
+	log.SetLevel(DebugLevel)
+	child.Debug("child debug")
+	clone.Debug("clone debug")
+
+	if !strings.Contains(buf.String(), "child debug") {
+		t.Errorf("expected SetLevel to apply to loggers derived earlier, got: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "clone debug") {
+		t.Errorf("expected a clone to keep its own level, got: %s", buf.String())
+	}
+}
+
+func TestClone(t *testing.T) {
+	parentOut := &bytes.Buffer{}
+	log := New(DebugLevel)
+	log.SetOutput(parentOut)
+	parent := log.WithField("request_id", "abc")
+
+	cloneOut := &bytes.Buffer{}
+	clone := parent.Clone()
+	clone.SetOutput(cloneOut)
+	clone.WithField("extra", "1").Debug("from clone")
+	parent.Debug("from parent")
+
+	if !strings.Contains(cloneOut.String(), "from clone") || !strings.Contains(cloneOut.String(), "request_id=abc") {
+		t.Errorf("expected clone entry with parent fields and level, got: %s", cloneOut.String())
+	}
+	if strings.Contains(parentOut.String(), "from clone") {
+		t.Errorf("expected clone output to be independent of the parent, got: %s", parentOut.String())
+	}
+	if !strings.Contains(parentOut.String(), "from parent") || strings.Contains(parentOut.String(), "extra=") {
+		t.Errorf("expected parent output to be unchanged, got: %s", parentOut.String())
+	}
+}
+
+func TestCloneFallbackAndClose(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "app-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentFallback := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetFallbackOutput(parentFallback)
+	log.SetOutput(f)
+	parent := log.WithGroup("req").WithField("id", "42")
+
+	cloneFallback := &bytes.Buffer{}
+	clone := parent.Clone()
+	clone.SetFallbackOutput(cloneFallback)
+	if err := clone.Close(); err != nil {
+		t.Fatalf("Close on the clone failed: %v", err)
+	}
+
+	clone.Info("from clone")
+	parent.Info("from parent")
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "from clone") || !strings.Contains(string(data), "from parent") {
+		t.Errorf("expected closing the clone to leave the shared file open, got: %s", data)
+	}
+	if !strings.Contains(string(data), "req.id=42") {
+		t.Errorf("expected the clone to keep the parent's group and fields, got: %s", data)
+	}
+
+	clone.Info("clone after close")
+	parent.Info("parent after close")
+	if !strings.Contains(cloneFallback.String(), "clone after close") || strings.Contains(cloneFallback.String(), "parent after close") {
+		t.Errorf("expected only clone entries in the clone's fallback, got: %s", cloneFallback.String())
+	}
+	if !strings.Contains(parentFallback.String(), "parent after close") || strings.Contains(parentFallback.String(), "clone after close") {
+		t.Errorf("expected only parent entries in the parent's fallback, got: %s", parentFallback.String())
+	}
+}
+
+func TestLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(WarnLevel)
+	log.SetOutput(buf)
+	child := log.WithFields(map[string]interface{}{"component": "api", "status": 200})
+
+	level := InfoLevel
+	if status := 503; status >= 500 {
+		level = ErrorLevel
+	}
+	child.Log(level, "request completed", map[string]interface{}{"status": 503, "path": "/orders"})
+	child.Log(InfoLevel, "filtered", nil)
+
+	output := buf.String()
+	if !strings.Contains(output, "level=ERROR") || !strings.Contains(output, `msg="request completed"`) {
+		t.Errorf("expected ERROR entry, got: %s", output)
+	}
+	for _, want := range []string{"component=api", "status=503", "path=/orders"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in output, got: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "status=200") {
+		t.Errorf("expected one-shot field to replace the logger's field, got: %s", output)
+	}
+	if strings.Contains(output, "filtered") {
+		t.Errorf("expected InfoLevel entry to be filtered, got: %s", output)
+	}
+
+	buf.Reset()
+	child.Warn("later")
+	if strings.Contains(buf.String(), "path=") || !strings.Contains(buf.String(), "status=200") {
+		t.Errorf("expected Log not to change the logger's fields, got: %s", buf.String())
+	}
+}
+
+func TestSetErrorOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetOutput(out)
+	log.SetErrorOutput(errOut)
+
+	child := log.WithGroup("req").WithField("id", "42")
+	child.Debug("debug message")
+	child.Info("info message")
+	child.Warn("warn message")
+	child.Error("error message")
+
+	if !strings.Contains(out.String(), "info message") || strings.Contains(out.String(), "warn message") || strings.Contains(out.String(), "error message") {
+		t.Errorf("expected only info entries on the main output, got: %s", out.String())
+	}
+	if strings.Contains(out.String(), "debug message") {
+		t.Errorf("expected debug entry to be discarded, got: %s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "warn message") || !strings.Contains(errOut.String(), "error message") || strings.Contains(errOut.String(), "info message") {
+		t.Errorf("expected only warn and error entries on the error output, got: %s", errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "req.id=42") {
+		t.Errorf("expected group and fields on the error output, got: %s", errOut.String())
+	}
+}
+
+func TestSetErrorOutputKeepsCustomHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	log := NewWithHandler(slog.NewJSONHandler(out, nil))
+	log.SetErrorOutput(errOut)
+
+	log.WithField("id", "42").Info("info message")
+	log.Error("error message")
+
+	if !strings.Contains(out.String(), `"msg":"info message"`) || !strings.Contains(out.String(), `"id":"42"`) {
+		t.Errorf("expected info entries from the custom handler, got: %s", out.String())
+	}
+	if strings.Contains(out.String(), "error message") || !strings.Contains(errOut.String(), "error message") {
+		t.Errorf("expected error entries on the error output only, got: %s / %s", out.String(), errOut.String())
+	}
+}
+
+func TestWithService(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	log := NewWithHandler(handler).WithService("billing")
+
+	log.Info("started")
+	if !strings.Contains(buf.String(), "service=billing") {
+		t.Errorf("expected service field on entry, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	log.WithField("service", "other").WithFields(map[string]interface{}{"service": "another"}).Info("child")
+	output := buf.String()
+	if !strings.Contains(output, "service=billing") {
+		t.Errorf("expected child to inherit service, got: %s", output)
+	}
+	if strings.Count(output, "service=") != 1 {
+		t.Errorf("expected service field not to be overwritten, got: %s", output)
+	}
+}
+
+func TestFieldOutputUnchanged(t *testing.T) {
+	noTime := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}}
+
+	for _, format := range []string{"text", "json"} {
+		newHandler := func(buf *bytes.Buffer) slog.Handler {
+			if format == "json" {
+				return slog.NewJSONHandler(buf, noTime)
+			}
+			return slog.NewTextHandler(buf, noTime)
+		}
+
+		got := &bytes.Buffer{}
+		log := NewWithHandler(newHandler(got)).
+			WithField("request_id", "abc").
+			WithService("api").
+			WithField("user", 42).
+			WithGroup("db").
+			WithField("host", "localhost")
+		log.Info("query")
+
+		want := &bytes.Buffer{}
+		slog.New(newHandler(want)).
+			With("request_id", "abc").
+			With(ServiceKey, "api").
+			With("user", 42).
+			WithGroup("db").
+			With("host", "localhost").
+			Info("query")
+
+		if got.String() != want.String() {
+			t.Errorf("%s: expected %q, got %q", format, want.String(), got.String())
+		}
+	}
+}
+
+func TestWithFieldSiblingsDoNotShareFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	parent := NewWithHandler(slog.NewTextHandler(buf, nil)).WithField("a", 1).WithField("b", 2)
+
+	first := parent.WithField("first", true)
+	second := parent.WithField("second", true)
+
+	first.Info("one")
+	if out := buf.String(); strings.Contains(out, "second") {
+		t.Errorf("expected sibling fields to be independent, got %q", out)
+	}
+	buf.Reset()
+
+	second.Info("two")
+	if out := buf.String(); strings.Contains(out, "first") {
+		t.Errorf("expected sibling fields to be independent, got %q", out)
+	}
+}
+
+// BenchmarkWithField measures deriving a request logger with one field and
+// logging once, the pattern on the hot request path.
+func BenchmarkWithField(b *testing.B) {
+	log := NewWithHandler(slog.NewJSONHandler(io.Discard, nil))
+	b.ReportAllocs()
+	for b.Loop() {
+		log.WithField("request_id", "abc123").Info("request")
+	}
+}
+
+// BenchmarkWithFieldSlogWith is the baseline: deriving through slog.Logger.With,
+// which clones the handler and pre-formats the field on every derivation.
+func BenchmarkWithFieldSlogWith(b *testing.B) {
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	b.ReportAllocs()
+	for b.Loop() {
+		log.With("request_id", "abc123").Info("request")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"debug":   DebugLevel,
+		"DEBUG":   DebugLevel,
+		"Info":    InfoLevel,
+		"warn":    WarnLevel,
+		"WARNING": WarnLevel,
+		"error":   ErrorLevel,
+		" ERROR ": ErrorLevel,
+	}
+	for input, want := range tests {
+		got, err := ParseLevel(input)
+		if err != nil {
+			t.Errorf("ParseLevel(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseLevel(%q): expected %v, got %v", input, want, got)
+		}
+	}
+
+	if _, err := ParseLevel("DEGUB"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// LogrNameKey is the field that carries the name set with logr's WithName.
+const LogrNameKey = "logger"
+
+// LogrSink returns a logr.LogSink that writes through l, for libraries such as
+// controller-runtime that accept a logr.Logger. V-level 0 logs at InfoLevel and
+// higher V-levels at DebugLevel; Error logs at ErrorLevel with the error in an
+// "error" field. Names added with WithName are joined with "/" in a "logger" field.
+func (l *Logger) LogrSink() logr.LogSink {
+	return &logrSink{logger: l}
+}
+
+// LogrLogger returns a logr.Logger backed by LogrSink:
+//
+//	ctrl.SetLogger(log.LogrLogger())
+func (l *Logger) LogrLogger() logr.Logger {
+	return logr.New(l.LogrSink())
+}
+
+// logrSink adapts a Logger to logr.LogSink.
+type logrSink struct {
+	logger *Logger
+	name   string
+}
+
+// Init implements logr.LogSink. Call depth is not used.
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+// Enabled reports whether entries at V-level level would be written.
+func (s *logrSink) Enabled(level int) bool {
+	return s.logger.logger.Enabled(context.Background(), logrLevel(level))
+}
+
+// Info logs msg at the level for V-level level.
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(logrLevel(level), msg, keysAndValues)
+}
+
+// Error logs msg at ErrorLevel with err in an "error" field.
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append([]interface{}{"error", err.Error()}, keysAndValues...)
+	}
+	s.log(slog.LevelError, msg, keysAndValues)
+}
+
+// WithValues returns a sink that adds keysAndValues to every entry.
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logrSink{logger: s.logger.withAttrs(s.attrs(keysAndValues)...), name: s.name}
+}
+
+// WithName returns a sink whose entries carry name appended to the current name.
+func (s *logrSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &logrSink{logger: s.logger, name: name}
+}
+
+// log writes an entry with the name, the logger's fields, and keysAndValues.
+func (s *logrSink) log(level slog.Level, msg string, keysAndValues []interface{}) {
+	l := s.logger
+	attrs := append(l.fields[:len(l.fields):len(l.fields)], s.attrs(keysAndValues)...)
+	if s.name != "" {
+		attrs = append([]slog.Attr{slog.String(LogrNameKey, s.name)}, attrs...)
+	}
+	l.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// attrs converts logr key/value pairs to attributes the way slog does, so a
+// missing value or a non-string key is reported under !BADKEY. Reserved keys
+// are dropped.
+func (s *logrSink) attrs(keysAndValues []interface{}) []slog.Attr {
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	r.Add(keysAndValues...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if !s.logger.reserved(a.Key) {
+			attrs = append(attrs, a)
+		}
+		return true
+	})
+	return attrs
+}
+
+// logrLevel maps a logr V-level to a slog level: 0 is Info, anything more
+// verbose is Debug.
+func logrLevel(level int) slog.Level {
+	if level > 0 {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogrLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetOutput(buf)
+
+	lr := log.WithField("app", "demo").LogrLogger().WithName("controller").WithName("reconciler").WithValues("namespace", "default")
+	lr.Info("reconciled", "object", "pod-1")
+	lr.V(1).Info("verbose detail")
+	lr.Error(errors.New("conflict"), "update failed", "attempt", 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries with V(1) filtered at InfoLevel, got %d: %s", len(lines), buf.String())
+	}
+	for _, want := range []string{"level=INFO", "msg=reconciled", "logger=controller/reconciler", "app=demo", "namespace=default", "object=pod-1"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("expected %s in info entry, got: %s", want, lines[0])
+		}
+	}
+	for _, want := range []string{"level=ERROR", `msg="update failed"`, "error=conflict", "attempt=2", "namespace=default"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected %s in error entry, got: %s", want, lines[1])
+		}
+	}
+}
+
+func TestLogrSinkVerbosity(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(DebugLevel)
+	log.SetOutput(buf)
+
+	lr := log.LogrLogger()
+	if !lr.V(0).Enabled() || !lr.V(2).Enabled() {
+		t.Error("expected all V-levels to be enabled at DebugLevel")
+	}
+	lr.V(2).Info("deep")
+	if !strings.Contains(buf.String(), "level=DEBUG") || !strings.Contains(buf.String(), "msg=deep") {
+		t.Errorf("expected V(2) to log at DEBUG, got: %s", buf.String())
+	}
+
+	quiet := New(WarnLevel).LogrLogger()
+	if quiet.V(0).Enabled() {
+		t.Error("expected V(0) to be disabled at WarnLevel")
+	}
+}
@@ -0,0 +1,40 @@
+// Package otellogger correlates Wayframe logs with OpenTelemetry traces.
+// It lives in its own package so applications that do not use OpenTelemetry
+// do not depend on it.
+//
+// Register Extractor once at startup; entries logged with the *Context methods
+// then carry the IDs of the span active in the context:
+//
+//	logger.RegisterContextExtractor(otellogger.Extractor)
+//
+//	ctx, span := tracer.Start(ctx, "charge")
+//	defer span.End()
+//	log.InfoContext(ctx, "charging card") // ... trace_id=4bf9... span_id=00f0...
+package otellogger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey is the field that carries the trace ID.
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the field that carries the span ID.
+	SpanIDKey = "span_id"
+)
+
+// Extractor is a logger.ContextExtractor that returns the trace and span IDs
+// of the span context in ctx, as lower-case hex. It returns nil when ctx
+// carries no valid span context.
+func Extractor(ctx context.Context) map[string]interface{} {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return map[string]interface{}{
+		TraceIDKey: sc.TraceID().String(),
+		SpanIDKey:  sc.SpanID().String(),
+	}
+}
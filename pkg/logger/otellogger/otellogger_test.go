@@ -0,0 +1,40 @@
+package otellogger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExtractor(t *testing.T) {
+	logger.RegisterContextExtractor(Extractor)
+
+	buf := &bytes.Buffer{}
+	log := logger.New(logger.InfoLevel)
+	log.SetOutput(buf)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	log.WithField("order", 7).InfoContext(ctx, "charging card")
+	out := buf.String()
+	for _, want := range []string{"trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7", "order=7"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in output, got: %s", want, out)
+		}
+	}
+
+	buf.Reset()
+	log.InfoContext(context.Background(), "no span")
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Errorf("expected no trace fields without a span, got: %s", buf.String())
+	}
+}
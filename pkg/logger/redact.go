@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+// RedactedValue replaces the values of fields named with RedactFields.
+const RedactedValue = "[REDACTED]"
+
+// RedactFields makes l replace the values of fields with any of the given keys
+// with RedactedValue when an entry is written, however the field was added.
+// Keys are matched case-insensitively and also inside groups, but values such
+// as maps and structs are not inspected. Calls are
+// cumulative. Call it on the root logger before deriving children, since
+// loggers derived earlier keep their previous settings.
+func (l *Logger) RedactFields(keys ...string) {
+	redact := make(map[string]bool, len(l.redact)+len(keys))
+	maps.Copy(redact, l.redact)
+	for _, key := range keys {
+		redact[strings.ToLower(key)] = true
+	}
+	l.redact = redact
+
+	handler := l.logger.Handler()
+	if rh, ok := handler.(*redactHandler); ok {
+		handler = rh.next
+	}
+	l.logger = slog.New(&redactHandler{next: handler, keys: redact})
+}
+
+// redactHandler replaces the values of attributes whose keys are in keys.
+type redactHandler struct {
+	next slog.Handler
+	keys map[string]bool
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted), keys: h.keys}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+// redact returns a with its value replaced if its key is redacted, recursing
+// into groups.
+func (h *redactHandler) redact(a slog.Attr) slog.Attr {
+	if h.keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, RedactedValue)
+	}
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	group := a.Value.Group()
+	redacted := make([]slog.Attr, len(group))
+	for i, ga := range group {
+		redacted[i] = h.redact(ga)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+}
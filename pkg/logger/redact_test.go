@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(InfoLevel)
+	log.SetOutput(buf)
+	log.RedactFields("password", "SSN")
+
+	log.WithField("password", "hunter2").WithFields(map[string]interface{}{
+		"ssn":  "123-45-6789",
+		"user": "alice",
+	}).Info("signup")
+
+	output := buf.String()
+	if strings.Contains(output, "hunter2") || strings.Contains(output, "123-45-6789") {
+		t.Errorf("expected redacted values to be hidden, got: %s", output)
+	}
+	if !strings.Contains(output, "password="+RedactedValue) || !strings.Contains(output, "ssn="+RedactedValue) {
+		t.Errorf("expected redacted fields to show %s, got: %s", RedactedValue, output)
+	}
+	if !strings.Contains(output, "user=alice") {
+		t.Errorf("expected other fields to be unchanged, got: %s", output)
+	}
+}
+
+func TestRedactFieldsCaseInsensitiveAndGrouped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewWithHandler(slog.NewTextHandler(buf, nil))
+	log.RedactFields("token")
+
+	log.WithGroup("auth").WithField("Token", "abc123").Info("login")
+	log.WithService("api").WithField("TOKEN", "def456").Info("refresh")
+	log.WithField("creds", slog.GroupValue(slog.String("token", "jkl012"))).Info("group value")
+
+	output := buf.String()
+	for _, secret := range []string{"abc123", "def456", "jkl012"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected %s to be redacted, got: %s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "auth.Token="+RedactedValue) {
+		t.Errorf("expected grouped field to be redacted, got: %s", output)
+	}
+}
+
+func TestRedactFieldsSurvivesSetOutput(t *testing.T) {
+	log := New(InfoLevel)
+	log.RedactFields("password")
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+
+	log.WithField("password", "hunter2").Info("login")
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("expected redaction after SetOutput, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,97 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"log/syslog"
+	"sync"
+)
+
+// NewSyslog creates a Logger that writes to a syslog daemon.
+// network and addr are passed to syslog.Dial; leave both empty to use the
+// local syslog socket. Each entry is sent with the syslog severity matching
+// its level (debug, info, warning, err) under the user facility.
+func NewSyslog(level Level, network, addr, tag string) (*Logger, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	out := &syslogOutput{writer: w}
+	levelVar := newLevelVar(level)
+	text := slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level: levelVar,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// syslog stamps its own time
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	handler := &syslogHandler{out: out, handler: text}
+	return &Logger{
+		logger: slog.New(handler),
+		level:  levelVar,
+		custom: handler,
+		closer: &onceCloser{c: w},
+	}, nil
+}
+
+// syslogOutput routes formatted entries to the syslog method for the
+// level currently being written. Access is serialized by mu.
+type syslogOutput struct {
+	mu     sync.Mutex
+	writer *syslog.Writer
+	level  slog.Level
+}
+
+// Write sends a formatted entry at the current level's syslog severity.
+func (o *syslogOutput) Write(p []byte) (int, error) {
+	msg := string(p)
+	var err error
+	switch {
+	case o.level >= slog.LevelError:
+		err = o.writer.Err(msg)
+	case o.level >= slog.LevelWarn:
+		err = o.writer.Warning(msg)
+	case o.level >= slog.LevelInfo:
+		err = o.writer.Info(msg)
+	default:
+		err = o.writer.Debug(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// syslogHandler is a slog.Handler that formats records as text and
+// writes them to syslog at the record's severity.
+type syslogHandler struct {
+	out     *syslogOutput
+	handler slog.Handler
+}
+
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	h.out.level = r.Level
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{out: h.out, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{out: h.out, handler: h.handler.WithGroup(name)}
+}
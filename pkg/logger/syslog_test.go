@@ -0,0 +1,95 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start syslog listener: %v", err)
+	}
+	defer conn.Close()
+
+	log, err := NewSyslog(InfoLevel, "udp", conn.LocalAddr().String(), "wayframe")
+	if err != nil {
+		t.Fatalf("failed to create syslog logger: %v", err)
+	}
+
+	log.WithField("user_id", 42).Error("disk full")
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no syslog message received: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// user facility (1) * 8 + err severity (3)
+	if !strings.HasPrefix(msg, "<11>") {
+		t.Errorf("expected priority <11>, got: %s", msg)
+	}
+	if !strings.Contains(msg, "wayframe") {
+		t.Errorf("expected tag in message, got: %s", msg)
+	}
+	if !strings.Contains(msg, `msg="disk full"`) || !strings.Contains(msg, "user_id=42") {
+		t.Errorf("expected message and field, got: %s", msg)
+	}
+}
+
+func TestNewSyslogLevelFiltering(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start syslog listener: %v", err)
+	}
+	defer conn.Close()
+
+	log, err := NewSyslog(WarnLevel, "udp", conn.LocalAddr().String(), "wayframe")
+	if err != nil {
+		t.Fatalf("failed to create syslog logger: %v", err)
+	}
+
+	log.Info("filtered")
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, _, err := conn.ReadFrom(buf); err == nil {
+		t.Errorf("expected info message to be filtered, got: %s", buf[:n])
+	}
+}
+
+func TestNewSyslogClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start syslog listener: %v", err)
+	}
+	defer ln.Close()
+
+	log, err := NewSyslog(InfoLevel, "tcp", ln.Addr().String(), "wayframe")
+	if err != nil {
+		t.Fatalf("failed to create syslog logger: %v", err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 64)); err != io.EOF {
+		t.Errorf("expected Close to close the syslog connection, got %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("expected a second Close to be a no-op, got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// NewSyslog is not supported on this platform and always returns an error.
+func NewSyslog(level Level, network, addr, tag string) (*Logger, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ClientOptions configures the HTTP client returned by NewClient.
+type ClientOptions struct {
+	// MaxAttempts is the total number of attempts per request, including the first.
+	// Defaults to 3.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on each attempt.
+	// Defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including Retry-After values.
+	// Defaults to 10s.
+	MaxDelay time.Duration
+	// Timeout is the overall timeout for a request including retries.
+	// Zero means no timeout.
+	Timeout time.Duration
+	// Transport is the underlying RoundTripper. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// NewClient creates an *http.Client that retries idempotent requests which fail
+// with 429 or 5xx responses or transport errors. Retries use exponential backoff
+// with jitter, honor the Retry-After header, and stop when the request context
+// is cancelled.
+func NewClient(opts ClientOptions) *http.Client {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 100 * time.Millisecond
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 10 * time.Second
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{opts: opts},
+		Timeout:   opts.Timeout,
+	}
+}
+
+// retryTransport is an http.RoundTripper that retries failed idempotent requests.
+type retryTransport struct {
+	opts ClientOptions
+}
+
+// RoundTrip executes the request, retrying according to the transport options.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return t.opts.Transport.RoundTrip(req)
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		// A RoundTripper must not modify req, so retries send a copy with a fresh body
+		attemptReq := req
+		if attempt > 1 && req.Body != nil && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err = t.opts.Transport.RoundTrip(attemptReq)
+		if attempt >= t.opts.MaxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			// Drain and close the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff computes the delay before the next attempt.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, t.opts.MaxDelay)
+		}
+	}
+
+	delay := t.opts.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > t.opts.MaxDelay {
+		delay = t.opts.MaxDelay
+	}
+	// Full jitter in the range [delay/2, delay)
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isIdempotent reports whether the request can be safely retried.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	// A body that cannot be rewound cannot be resent
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry reports whether a response or error warrants another attempt.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP-date form.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
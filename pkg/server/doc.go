@@ -17,6 +17,10 @@
 //
 //	srv.Start(30 * time.Second)
 //
+// Routes must be registered before Start; registering one afterwards panics,
+// since it is usually a bug. Apps that add routes at runtime, such as from
+// plugins, can set Config.DynamicRoutes to allow Handle after Start.
+//
 // # Middleware
 //
 // Add middleware to process requests:
@@ -27,20 +31,241 @@
 // Middleware is applied in the order it's added. The first middleware
 // added is the outermost wrapper.
 //
+// Chain composes several middlewares into one reusable stack with the same order:
+//
+//	apiStack := server.Chain(server.RequestIDMiddleware(), server.LoggingMiddleware(log))
+//	srv.Use(apiStack)
+//
+// Middleware added with Use wraps each registered handler, so it only runs for
+// requests that match a pattern. Middleware added with Pre wraps the whole mux
+// and runs before routing:
+//
+//	srv.Pre(server.StripTrailingSlashMiddleware())
+//
+// # Fallback Handlers
+//
+// NotFound and MethodNotAllowed replace the default plain-text responses for
+// unmatched requests. Middleware added with Use before the call wraps them too:
+//
+//	srv.Use(server.LoggingMiddleware(log))
+//	srv.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    w.Header().Set("Content-Type", "application/json")
+//	    w.WriteHeader(http.StatusNotFound)
+//	    w.Write([]byte(`{"error":"not found"}`))
+//	}))
+//
+// # Responses
+//
+// Negotiate picks a content type from the request's Accept header, and
+// WriteJSON and WriteXML encode a value with the matching Content-Type:
+//
+//	if server.Negotiate(r, "application/json", "application/xml") == "application/xml" {
+//	    server.WriteXML(w, http.StatusOK, user)
+//	    return
+//	}
+//	server.WriteJSON(w, http.StatusOK, user)
+//
+// API errors can be returned as a consistent JSON shape with Error and
+// WriteError. JSONHandler adapts a function returning a value and an error:
+//
+//	srv.Handle("GET /users/{id}", server.JSONHandler(func(r *http.Request) (interface{}, error) {
+//	    return nil, server.NewError(http.StatusNotFound, "user not found")
+//	}))
+//	// 404 {"code":404,"message":"user not found"}
+//
 // # Built-in Middleware
 //
 // The package includes common middleware:
-//   - LoggingMiddleware: Logs each request with method, path, and duration
-//   - RecoveryMiddleware: Recovers from panics and returns 500 errors
+//   - LoggingMiddleware: Logs each request with method, route template, and duration
+//   - RecoveryMiddleware: Recovers from panics and returns 500 (or the panic error's status)
+//   - MetricsMiddleware: Records request counts, status classes, and latency
+//   - StripTrailingSlashMiddleware: Redirects or rewrites /path/ to /path
+//   - CanonicalHostMiddleware: Redirects other hosts, such as www, to the canonical host
+//   - RequestIDMiddleware: Assigns and echoes an X-Request-ID per request and adds
+//     it as request_id to the context logger
+//   - CorrelationMiddleware: Propagates an X-Correlation-ID across services
+//   - DecompressMiddleware: Decompresses gzip/deflate request bodies with a size cap
+//   - GzipMiddleware: Compresses responses for clients that accept gzip
+//   - ETagMiddleware: Adds body-hash ETags and answers If-None-Match with 304
+//   - LoadShedMiddleware: Returns 503 when too many requests are in flight
+//   - PerClientConcurrencyMiddleware: Returns 429 when one client has too many requests in flight
+//   - RequireHeadersMiddleware: Returns 400 when a required header is missing
+//     and 403 when it does not have the required value
+//   - RateLimitMiddleware: Limits each client's request rate with a token bucket,
+//     reporting the remaining quota in X-RateLimit-* headers on every response
+//   - ECSAccessLogMiddleware: Logs requests with Elastic Common Schema field names,
+//     optionally with a numeric duration_ms latency field
+//   - LoggerMiddleware: Stores a logger in the request context for logger.FromContext
+//   - TimeoutMiddleware: Returns 503 when a handler runs longer than a limit
+//   - BodyTimeoutMiddleware: Extends the body read deadline for slow uploads
+//   - IdempotencyMiddleware: Replays the stored response for a repeated Idempotency-Key
+//   - ServerInfoMiddleware: Adds X-Server-Start and optionally X-Server-Instance headers
+//   - RequestLimitsMiddleware: Returns 431 for oversized query strings or too many headers
+//     (installed ahead of routing by Config.MaxQueryStringBytes and MaxHeaderCount)
+//
+// RecoveryMiddleware accepts RecoveryOptions to customize the error body:
+//
+//	srv.Use(server.RecoveryMiddleware(log, server.RecoveryOptions{
+//	    ResponseBody: []byte(`{"error":"internal"}`),
+//	    ContentType:  "application/json",
+//	}))
+//
+// To forward panics to an error-tracking service, use
+// RecoveryMiddlewareWithReporter (or RecoveryOptions.Reporter), which receives
+// the recovered value, the request, and the stack trace.
+//
+// IdempotencyMiddleware takes an IdempotencyStore; MemoryIdempotencyStore
+// suits a single instance, while a shared store lets retries reach any instance:
+//
+//	srv.Handle("POST /payments", server.IdempotencyMiddleware(
+//	    server.NewMemoryIdempotencyStore(24*time.Hour))(payments))
+//
+// Middleware that observes or transforms responses is built on the
+// responsewriter package, so it composes in any order. Register GzipMiddleware
+// before ETagMiddleware to compute ETags on the uncompressed body.
+//
+// HandleWithTimeout applies TimeoutMiddleware to a single route. When a global
+// TimeoutMiddleware is also registered with Use, the tighter timeout wins:
+//
+//	srv.Use(server.TimeoutMiddleware(10 * time.Second))
+//	srv.HandleWithTimeout("GET /health", health, time.Second)
+//	srv.HandleWithTimeout("GET /reports/export", export, 2*time.Minute) // still 10s
+//
+// Logging and metrics label requests by their registered route template, such
+// as /users/{id}, rather than the literal path; RouteTemplate exposes the same
+// mapping for custom middleware.
+//
+// IDs are generated by the package-level IDGenerator, which can be replaced
+// to use another scheme such as ULIDs.
 //
 // # Graceful Shutdown
 //
 // The Start method handles graceful shutdown automatically:
 //   - Listens for SIGINT and SIGTERM signals
 //   - Stops accepting new connections
-//   - Waits for existing requests to complete (up to timeout)
+//   - Waits for existing requests to complete (up to timeout), periodically
+//     reporting how many connections are still open (see ActiveConns)
+//   - Runs OnShutdown hooks with the shutdown context
+//   - Runs OnExit hooks once serving has stopped, even if shutdown timed out
 //   - Returns when shutdown is complete
 //
+// Apps that already own signal handling can set Config.DisableSignalHandling
+// and stop the server through StartContext's context or an explicit Shutdown:
+//
+//	srv := server.New(server.Config{Addr: ":8080", DisableSignalHandling: true})
+//	go srv.StartContext(ctx, 30*time.Second)
+//
+// StartWithReady closes a channel once the listener is bound, so tests can
+// make requests without sleeping; Addr then reports the bound address:
+//
+//	ready := make(chan struct{})
+//	go srv.StartWithReady(30*time.Second, ready)
+//	<-ready
+//	resp, err := http.Get("http://" + srv.Addr() + "/health")
+//
+// After a container restart the previous port can linger briefly. Set
+// Config.ListenRetries and ListenRetryDelay to retry binding while the address
+// is in use; other listen errors still fail immediately.
+//
+// Hooks can adapt to the time left in the shutdown budget:
+//
+//	srv.OnShutdown(func(ctx context.Context) {
+//	    if server.RemainingBudget(ctx) < time.Second {
+//	        db.FlushFast()
+//	        return
+//	    }
+//	    db.Flush()
+//	})
+//
+// ObserveShutdown reports progress through the signaled, draining, hooks, and
+// complete phases with the time elapsed, so slow shutdowns are visible:
+//
+//	srv.ObserveShutdown(func(phase server.ShutdownPhase, elapsed time.Duration) {
+//	    log.Infof("shutdown %s after %v", phase, elapsed)
+//	})
+//
+// Apps that run several servers can shut them down in a defined order with a
+// Group. Servers are stopped in the order given, sharing one timeout:
+//
+//	group := server.NewGroup(publicSrv, adminSrv)
+//	if err := group.Start(30 * time.Second); err != nil {
+//	    log.Errorf("server group: %v", err)
+//	}
+//
+// # TLS
+//
+// Set CertFile and KeyFile to serve HTTPS. Certificates can be rotated without
+// a restart, for example after a Let's Encrypt renewal:
+//
+//	srv := server.New(server.Config{Addr: ":443", CertFile: "cert.pem", KeyFile: "key.pem"})
+//	// later, after the files are replaced:
+//	if err := srv.ReloadCertificates(); err != nil {
+//	    log.Errorf("certificate reload failed: %v", err)
+//	}
+//
+// # Metrics
+//
+// A dependency-free metrics collector can be exposed as JSON:
+//
+//	srv.Use(server.MetricsMiddleware(srv.Metrics()))
+//	srv.Handle("/metrics", srv.MetricsJSONHandler())
+//
+// Each route reports its request count, latency percentiles, and request and
+// response body size percentiles (http_request_size_bytes and
+// http_response_size_bytes).
+//
+// # Single-Page Apps
+//
+// SPA serves a directory of static files and answers unknown paths with its
+// index.html, so client-side routing works. Paths under APIPrefix get a 404:
+//
+//	srv.SPA("/app", "./web/dist", server.SPAOptions{APIPrefix: "/app/api/"})
+//
+// # Reverse Proxy
+//
+// ReverseProxy forwards requests to an internal service, setting
+// X-Forwarded-* headers and answering 502 in the WriteError format when the
+// service is unreachable. Middleware applies as for any other handler:
+//
+//	srv.Handle("/billing/", server.ReverseProxy("http://billing.internal:8080",
+//	    server.ProxyOptions{StripPrefix: "/billing"}))
+//
+// # API Specs
+//
+// ServeSpec serves an OpenAPI or similar spec file as application/json or
+// application/yaml, with an ETag so clients get 304 Not Modified until it
+// changes:
+//
+//	srv.ServeSpec("/openapi.yaml", "./api/openapi.yaml")
+//
+// # Profiling
+//
+// EnablePprof registers the net/http/pprof handlers under a prefix, wrapped in
+// the given middleware so profiling can be kept behind authentication:
+//
+//	srv.EnablePprof("/debug/pprof", basicAuth)
+//
+// # Version Endpoint
+//
+// VersionHandler exposes build information as JSON, falling back to the
+// information embedded by go build for any empty fields:
+//
+//	srv.Handle("/version", server.VersionHandler(server.VersionInfo{
+//	    Version: version,
+//	    Commit:  commit,
+//	}))
+//
+// # Retrying HTTP Client
+//
+// NewClient returns an *http.Client that retries idempotent requests on 429
+// and 5xx responses with exponential backoff and jitter:
+//
+//	client := server.NewClient(server.ClientOptions{
+//	    MaxAttempts: 5,
+//	    BaseDelay:   200 * time.Millisecond,
+//	})
+//	resp, err := client.Get("http://upstream/api")
+//
 // # Example
 //
 //	srv := server.New(server.Config{Addr: ":8080"})
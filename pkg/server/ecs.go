@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+	"github.com/Waryway/Wayframe/pkg/server/responsewriter"
+)
+
+// AccessLogOptions configures ECSAccessLogMiddleware.
+type AccessLogOptions struct {
+	// DurationMS adds a duration_ms field holding the request latency as a
+	// fractional number of milliseconds (e.g. duration_ms=1.234), which is
+	// easier to aggregate than a formatted duration.
+	DurationMS bool
+}
+
+// ECSAccessLogMiddleware logs one structured entry per request using Elastic
+// Common Schema field names, so access logs index cleanly in an ELK stack
+// without ingest-time remapping. Pair it with a JSON logger:
+//
+//	log := logger.NewWithHandler(slog.NewJSONHandler(os.Stdout, nil))
+//	srv.Use(server.ECSAccessLogMiddleware(log))
+//
+// Fields are emitted as dotted keys (e.g. "http.request.method"), which
+// Elasticsearch expands into the nested ECS structure. event.duration is in
+// nanoseconds, as ECS specifies.
+func ECSAccessLogMiddleware(log *logger.Logger, opts ...AccessLogOptions) Middleware {
+	var o AccessLogOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := responsewriter.New(w)
+			next.ServeHTTP(rec, r)
+			duration := time.Since(start)
+
+			fields := map[string]interface{}{
+				"event.kind":                "event",
+				"event.category":            "web",
+				"event.duration":            duration.Nanoseconds(),
+				"http.version":              strings.TrimPrefix(r.Proto, "HTTP/"),
+				"http.request.method":       r.Method,
+				"http.response.status_code": rec.Status(),
+				"http.response.body.bytes":  rec.BytesWritten(),
+				"url.path":                  r.URL.Path,
+				"client.ip":                 clientIP(r),
+				"user_agent.original":       r.UserAgent(),
+			}
+			if o.DurationMS {
+				fields["duration_ms"] = float64(duration) / float64(time.Millisecond)
+			}
+			if r.URL.RawQuery != "" {
+				fields["url.query"] = r.URL.RawQuery
+			}
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				fields["http.request.id"] = id
+			}
+
+			log.WithFields(fields).Infof("%s %s", r.Method, r.URL.Path)
+		})
+	}
+}
+
+// clientIP returns the host part of the request's remote address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
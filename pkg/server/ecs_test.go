@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+func TestECSAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithHandler(slog.NewJSONHandler(&buf, nil))
+
+	handler := ECSAccessLogMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+
+	req := httptest.NewRequest("POST", "/users?debug=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log output %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"http.request.method":       "POST",
+		"http.response.status_code": float64(http.StatusCreated),
+		"http.response.body.bytes":  float64(len("created")),
+		"url.path":                  "/users",
+		"url.query":                 "debug=1",
+		"user_agent.original":       "test-agent",
+		"client.ip":                 "192.0.2.1",
+	}
+	for key, want := range expected {
+		if got, ok := entry[key]; !ok || got != want {
+			t.Errorf("expected %s=%v, got %v", key, want, got)
+		}
+	}
+	if d, ok := entry["event.duration"].(float64); !ok || d < 0 {
+		t.Errorf("expected numeric event.duration, got %v", entry["event.duration"])
+	}
+}
+
+func TestECSAccessLogMiddlewareDurationMS(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithHandler(slog.NewTextHandler(&buf, nil))
+
+	handler := ECSAccessLogMiddleware(log, AccessLogOptions{DurationMS: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+
+	var value string
+	for _, field := range strings.Fields(buf.String()) {
+		if v, ok := strings.CutPrefix(field, "duration_ms="); ok {
+			value = v
+		}
+	}
+	ms, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		t.Fatalf("expected numeric duration_ms, got %q in %s", value, buf.String())
+	}
+	if ms < 1 {
+		t.Errorf("expected duration_ms of at least 1, got %v", ms)
+	}
+}
+
+func TestECSAccessLogMiddlewareNoDurationMSByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithHandler(slog.NewTextHandler(&buf, nil))
+
+	ECSAccessLogMiddleware(log)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if strings.Contains(buf.String(), "duration_ms") {
+		t.Errorf("expected no duration_ms field by default, got: %s", buf.String())
+	}
+}
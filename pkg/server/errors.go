@@ -0,0 +1,51 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an API error rendered as JSON by WriteError:
+//
+//	{"code": 400, "message": "invalid email", "details": {"field": "email"}}
+//
+// It implements StatusCode, so RecoveryMiddleware also maps a panic with an
+// *Error to its status.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// NewError creates an Error with the given HTTP status code and message.
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetails returns a copy of e with details attached.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Error implements the error interface, returning the message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status code of the error.
+func (e *Error) StatusCode() int {
+	return e.Code
+}
+
+// WriteError renders err as a JSON error response. If err is or wraps an
+// *Error, it is written with its own status code. Any other error is written
+// as a 500 with a generic message, so internal details are not leaked.
+func WriteError(w http.ResponseWriter, err error) error {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code < 400 || apiErr.Code > 599 {
+		apiErr = NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	return WriteJSON(w, apiErr.Code, apiErr)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := NewError(http.StatusBadRequest, "invalid email").WithDetails(map[string]string{"field": "email"})
+	if writeErr := WriteError(w, fmt.Errorf("validating: %w", err)); writeErr != nil {
+		t.Fatalf("WriteError failed: %v", writeErr)
+	}
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %s", ct)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body["code"] != float64(400) || body["message"] != "invalid email" {
+		t.Errorf("unexpected error body: %v", body)
+	}
+	if details, ok := body["details"].(map[string]interface{}); !ok || details["field"] != "email" {
+		t.Errorf("expected details with field, got %v", body["details"])
+	}
+}
+
+func TestWriteErrorHidesInternalErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteError(w, errors.New("connection refused to db-primary:5432"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body["message"] != "Internal Server Error" {
+		t.Errorf("expected generic message, got %v", body["message"])
+	}
+	if _, ok := body["details"]; ok {
+		t.Error("expected details to be omitted")
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	handler := JSONHandler(func(r *http.Request) (interface{}, error) {
+		if r.URL.Query().Get("id") == "" {
+			return nil, NewError(http.StatusNotFound, "user not found")
+		}
+		return map[string]string{"id": r.URL.Query().Get("id")}, nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?id=42", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestRecoveryMiddlewareWritesError(t *testing.T) {
+	handler := RecoveryMiddleware(&mockLogger{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(NewError(http.StatusConflict, "version conflict"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", w.Code)
+	}
+	var body Error
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON error body, got %q", w.Body.String())
+	}
+	if body.Message != "version conflict" {
+		t.Errorf("expected message 'version conflict', got %q", body.Message)
+	}
+}
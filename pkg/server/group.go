@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Group runs several servers together, such as a public API and an internal
+// admin or metrics server, and shuts them down in a defined order. It is
+// unrelated to grouping routes within a single server.
+type Group struct {
+	servers []*Server
+}
+
+// NewGroup creates a group of servers. Servers are shut down in the order
+// given, so list public-facing servers first to stop accepting external
+// traffic before internal endpoints go away.
+func NewGroup(servers ...*Server) *Group {
+	return &Group{servers: servers}
+}
+
+// Add appends a server to the group; it is shut down after those added before it.
+func (g *Group) Add(s *Server) {
+	g.servers = append(g.servers, s)
+}
+
+// Start starts all servers and blocks until a SIGINT or SIGTERM is received or
+// any server fails, then shuts them down in order. The shutdown timeout is a
+// single budget shared by all servers. If any server has
+// Config.DisableSignalHandling set, the group does not listen for signals
+// either, leaving them to the app; use StartContext to stop it then.
+func (g *Group) Start(shutdownTimeout time.Duration) error {
+	ctx, stop := context.WithCancel(context.Background())
+	if g.handleSignals() {
+		ctx, stop = signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	}
+	defer stop()
+	return g.StartContext(ctx, shutdownTimeout)
+}
+
+// handleSignals reports whether no server in the group disables signal handling.
+func (g *Group) handleSignals() bool {
+	for _, s := range g.servers {
+		if s.disableSignals {
+			return false
+		}
+	}
+	return true
+}
+
+// StartContext is like Start but shuts the servers down when ctx is done
+// instead of listening for signals. Each server's observers are told
+// ShutdownSignaled when ctx is done. The returned error joins the failures of
+// every server.
+func (g *Group) StartContext(ctx context.Context, shutdownTimeout time.Duration) error {
+	errChan := make(chan error, len(g.servers))
+	var wg sync.WaitGroup
+	for _, s := range g.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.listenAndServe(nil); err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("server %s failed: %w", s.httpServer.Addr, err)
+			}
+		}()
+	}
+
+	var errs []error
+	var start time.Time
+	select {
+	case <-ctx.Done():
+		fmt.Println("Shutting down server group gracefully...")
+		start = time.Now()
+		for _, s := range g.servers {
+			s.notifyShutdown(ShutdownSignaled, start)
+		}
+	case err := <-errChan:
+		errs = append(errs, err)
+		start = time.Now()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	for _, s := range g.servers {
+		if err := s.shutdown(shutdownCtx, start); err != nil {
+			errs = append(errs, fmt.Errorf("server %s forced to shutdown with %d active connections: %w",
+				s.httpServer.Addr, s.ActiveConns(), err))
+		}
+	}
+
+	// Serve returns as soon as Shutdown is called, so this does not outlast the timeout
+	wg.Wait()
+	close(errChan)
+	for err := range errChan {
+		errs = append(errs, err)
+	}
+	for _, s := range g.servers {
+		s.runExitHooks()
+	}
+	return errors.Join(errs...)
+}
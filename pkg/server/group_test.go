@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroupShutdownOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	public := New(Config{Addr: "127.0.0.1:0"})
+	public.OnShutdown(record("public"))
+	admin := New(Config{Addr: "127.0.0.1:0"})
+	admin.OnShutdown(record("admin"))
+
+	group := NewGroup(public, admin)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- group.StartContext(ctx, 5*time.Second)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("group did not return after shutdown")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "public" || order[1] != "admin" {
+		t.Errorf("expected shutdown order [public admin], got %v", order)
+	}
+}
+
+func TestGroupStopsWhenServerFails(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	var shutDown bool
+	healthy := New(Config{Addr: "127.0.0.1:0"})
+	healthy.OnShutdown(func(ctx context.Context) { shutDown = true })
+	conflicting := New(Config{Addr: ln.Addr().String()})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewGroup(healthy, conflicting).StartContext(context.Background(), 5*time.Second)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error from the server that failed to listen")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("group did not return after a server failed")
+	}
+	if !shutDown {
+		t.Error("expected the healthy server to be shut down")
+	}
+}
+
+func TestGroupReportsEveryFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	first := New(Config{Addr: ln.Addr().String()})
+	second := New(Config{Addr: ln.Addr().String()})
+	err = NewGroup(first, second).StartContext(context.Background(), 5*time.Second)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("expected both listen failures to be reported, got %v", err)
+	}
+}
+
+func TestGroupReportsShutdownSignaled(t *testing.T) {
+	var mu sync.Mutex
+	var phases []ShutdownPhase
+	observe := func(phase ShutdownPhase, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases = append(phases, phase)
+	}
+
+	public := New(Config{Addr: "127.0.0.1:0"})
+	public.ObserveShutdown(observe)
+	admin := New(Config{Addr: "127.0.0.1:0"})
+	admin.ObserveShutdown(observe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewGroup(public, admin).StartContext(ctx, 5*time.Second); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(phases) != 8 || phases[0] != ShutdownSignaled || phases[1] != ShutdownSignaled {
+		t.Errorf("expected both servers to report ShutdownSignaled before draining, got %v", phases)
+	}
+}
+
+func TestGroupSignalHandling(t *testing.T) {
+	if !NewGroup(New(Config{}), New(Config{})).handleSignals() {
+		t.Error("expected the group to handle signals by default")
+	}
+	if NewGroup(New(Config{}), New(Config{DisableSignalHandling: true})).handleSignals() {
+		t.Error("expected DisableSignalHandling on a member to stop the group handling signals")
+	}
+}
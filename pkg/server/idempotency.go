@@ -0,0 +1,243 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+	"github.com/Waryway/Wayframe/pkg/server/responsewriter"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying an idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on responses replayed from an
+	// IdempotencyStore.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// CachedResponse is a response recorded by IdempotencyMiddleware.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore stores responses by idempotency key for
+// IdempotencyMiddleware. Implementations backed by an external store, such as
+// Redis, let retries reach any instance; Lock should then take a lock shared
+// by all instances.
+type IdempotencyStore interface {
+	// Lock blocks until the caller holds the lock for key, or ctx is done, and
+	// returns a function that releases it.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+	// Get returns the response stored for key, if any.
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	// Set stores resp for key.
+	Set(ctx context.Context, key string, resp *CachedResponse) error
+}
+
+// IdempotencyMiddleware makes POST, PUT, PATCH, and DELETE requests that carry
+// an Idempotency-Key header safe to retry. The first request with a key runs
+// the handler and its response is stored; later requests with the same key,
+// method, and path receive the stored response, marked with an
+// Idempotent-Replayed header, without running the handler again. Concurrent
+// requests with the same key wait for each other through the store's Lock.
+//
+// Responses with a 5xx status are not stored, so failed requests can be
+// retried. Only headers the handler set are stored, so headers added by outer
+// middleware, such as a request ID, are not replayed. Requests without the
+// header pass through unchanged; an invalid key is rejected with 400, and
+// store errors fail the request with 500. A failure to store a response that
+// has already been sent is logged through logger.FromContext instead.
+func IdempotencyMiddleware(store IdempotencyStore) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !mutatingMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !validID(key) {
+				http.Error(w, "invalid "+IdempotencyKeyHeader+" header", http.StatusBadRequest)
+				return
+			}
+
+			// Scope keys to the endpoint so a reused key cannot replay another route's response
+			key = r.Method + " " + r.URL.Path + " " + key
+
+			ctx := r.Context()
+			unlock, err := store.Lock(ctx, key)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			defer unlock()
+
+			cached, ok, err := store.Get(ctx, key)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if ok {
+				for name, values := range cached.Header {
+					w.Header()[name] = append([]string(nil), values...)
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(cached.Status)
+				w.Write(cached.Body)
+				return
+			}
+
+			// Headers set before the handler, such as a request ID from outer
+			// middleware, belong to this request and must not be replayed
+			before := w.Header().Clone()
+			var body bytes.Buffer
+			rec := responsewriter.New(w)
+			rec.OnWriteHeader(func(int) {
+				rec.SetBodyWriter(io.MultiWriter(w, &body))
+			})
+			next.ServeHTTP(rec, r)
+
+			if rec.Status() >= 500 {
+				return
+			}
+			err = store.Set(ctx, key, &CachedResponse{
+				Status: rec.Status(),
+				Header: headerChanges(before, w.Header()),
+				Body:   body.Bytes(),
+			})
+			if err != nil {
+				// The response is already sent, so a retry simply runs the handler again
+				logger.FromContext(ctx).Errorf("idempotency: failed to store response: %v", err)
+			}
+		})
+	}
+}
+
+// headerChanges returns the headers in after that are missing from before or
+// have different values.
+func headerChanges(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			changed[name] = append([]string(nil), values...)
+		}
+	}
+	return changed
+}
+
+// mutatingMethod reports whether method changes server state.
+func mutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. It suits a single
+// instance; use a shared store when several instances serve the same clients.
+type MemoryIdempotencyStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	responses map[string]memoryEntry
+	locks     map[string]*keyLock
+}
+
+// memoryEntry is a stored response and when it expires.
+type memoryEntry struct {
+	resp    *CachedResponse
+	expires time.Time
+}
+
+// keyLock is a lock for one key, counting the requests holding or waiting for it.
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewMemoryIdempotencyStore returns a MemoryIdempotencyStore that keeps
+// responses for ttl, or forever if ttl is 0.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:       ttl,
+		responses: make(map[string]memoryEntry),
+		locks:     make(map[string]*keyLock),
+	}
+}
+
+// Lock blocks until the caller holds the lock for key or ctx is done.
+func (s *MemoryIdempotencyStore) Lock(ctx context.Context, key string) (func(), error) {
+	s.mu.Lock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = &keyLock{ch: make(chan struct{}, 1)}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	select {
+	case l.ch <- struct{}{}:
+	case <-ctx.Done():
+		s.release(key, l)
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-l.ch
+		s.release(key, l)
+	}, nil
+}
+
+// release drops a reference to l, removing it once no request needs it.
+func (s *MemoryIdempotencyStore) release(key string, l *keyLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(s.locks, key)
+	}
+}
+
+// Get returns the unexpired response stored for key, if any.
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (*CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.responses[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(s.responses, key)
+		return nil, false, nil
+	}
+	return entry.resp, true, nil
+}
+
+// Set stores resp for key, dropping any expired responses.
+func (s *MemoryIdempotencyStore) Set(_ context.Context, key string, resp *CachedResponse) error {
+	now := time.Now()
+	entry := memoryEntry{resp: resp}
+	if s.ttl > 0 {
+		entry.expires = now.Add(s.ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ttl > 0 {
+		for k, e := range s.responses {
+			if now.After(e.expires) {
+				delete(s.responses, k)
+			}
+		}
+	}
+	s.responses[key] = entry
+	return nil
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+func TestIdempotencyMiddlewareReplays(t *testing.T) {
+	var calls atomic.Int64
+	srv := New(Config{Addr: ":0"})
+	srv.Use(IdempotencyMiddleware(NewMemoryIdempotencyStore(time.Hour)))
+	srv.HandleFunc("POST /payments", func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Location", fmt.Sprintf("/payments/%d", n))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "payment %d", n)
+	})
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/payments", nil)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("key-1")
+	second := send("key-1")
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != "payment 1" {
+		t.Errorf("expected cached 201 \"payment 1\", got %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get("Location") != "/payments/1" {
+		t.Errorf("expected cached Location header, got %q", second.Header().Get("Location"))
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("expected only the replay to be marked, got first=%q second=%q",
+			first.Header().Get(IdempotentReplayedHeader), second.Header().Get(IdempotentReplayedHeader))
+	}
+
+	if w := send("key-2"); w.Body.String() != "payment 2" {
+		t.Errorf("expected a new key to run the handler, got %q", w.Body.String())
+	}
+	if w := send(""); w.Body.String() != "payment 3" {
+		t.Errorf("expected a request without a key to run the handler, got %q", w.Body.String())
+	}
+	if w := send("bad key"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid key, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyMiddlewareSkipsServerErrors(t *testing.T) {
+	var calls atomic.Int64
+	handler := IdempotencyMiddleware(NewMemoryIdempotencyStore(0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("PUT", "/orders/1", nil)
+		req.Header.Set(IdempotencyKeyHeader, "retry-me")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a 5xx response not to be cached, handler ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotencyMiddlewareSerializesDuplicates(t *testing.T) {
+	var calls atomic.Int64
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := IdempotencyMiddleware(NewMemoryIdempotencyStore(0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		close(started)
+		<-release
+		fmt.Fprint(w, "done")
+	}))
+
+	bodies := make([]string, 2)
+	var wg sync.WaitGroup
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/transfers", nil)
+			req.Header.Set(IdempotencyKeyHeader, "same")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			bodies[i] = w.Body.String()
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected concurrent duplicates to run the handler once, ran %d times", calls.Load())
+	}
+	if bodies[0] != "done" || bodies[1] != "done" {
+		t.Errorf("expected both requests to get the same response, got %q", bodies)
+	}
+}
+
+func TestMemoryIdempotencyStoreLockHonorsContext(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	unlock, err := store.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(ctx, "k"); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline error while the key is locked, got %v", err)
+	}
+}
+
+func TestIdempotencyMiddlewareReplaysOnlyHandlerHeaders(t *testing.T) {
+	var requests atomic.Int64
+	srv := New(Config{Addr: ":0"})
+	srv.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-ID", fmt.Sprintf("req-%d", requests.Add(1)))
+			next.ServeHTTP(w, r)
+		})
+	})
+	srv.Use(IdempotencyMiddleware(NewMemoryIdempotencyStore(time.Hour)))
+	srv.HandleFunc("POST /payments", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/payments/1")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for _, want := range []string{"req-1", "req-2"} {
+		req := httptest.NewRequest("POST", "/payments", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		if got := w.Header().Get("X-Request-ID"); got != want {
+			t.Errorf("expected X-Request-ID %s, got %s", want, got)
+		}
+		if got := w.Header().Get("Location"); got != "/payments/1" {
+			t.Errorf("expected the handler's Location header, got %q", got)
+		}
+	}
+}
+
+// failingSetStore is a MemoryIdempotencyStore whose Set always fails.
+type failingSetStore struct {
+	*MemoryIdempotencyStore
+}
+
+func (failingSetStore) Set(context.Context, string, *CachedResponse) error {
+	return errors.New("store unavailable")
+}
+
+func TestIdempotencyMiddlewareLogsStoreErrors(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := logger.New(logger.InfoLevel)
+	log.SetOutput(out)
+
+	handler := IdempotencyMiddleware(failingSetStore{NewMemoryIdempotencyStore(0)})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	req := httptest.NewRequest("POST", "/payments", nil)
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	req = req.WithContext(logger.IntoContext(req.Context(), log))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected the handler's response to be sent, got %d", w.Code)
+	}
+	if !strings.Contains(out.String(), "level=ERROR") || !strings.Contains(out.String(), "store unavailable") {
+		t.Errorf("expected the store error to be logged, got: %s", out.String())
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/server/responsewriter"
+)
+
+// reservoirSize is the number of latency samples retained per route.
+const reservoirSize = 1024
+
+// Metrics is a lightweight, dependency-free in-memory metrics collector.
+// It tracks per-route request counts, status class counts, and latency and
+// body size percentiles.
+type Metrics struct {
+	mu            sync.Mutex
+	total         int64
+	statusClasses map[string]int64
+	routes        map[string]*routeMetrics
+}
+
+// routeMetrics holds the counters and samples for a single route.
+type routeMetrics struct {
+	count         int64
+	latencies     reservoir
+	requestSizes  reservoir
+	responseSizes reservoir
+}
+
+// reservoir keeps a uniform random sample of observations (Algorithm R).
+type reservoir struct {
+	samples []float64
+	seen    int64
+}
+
+func (r *reservoir) add(v float64) {
+	r.seen++
+	if len(r.samples) < reservoirSize {
+		r.samples = append(r.samples, v)
+		return
+	}
+	if i := rand.Int63n(r.seen); i < reservoirSize {
+		r.samples[i] = v
+	}
+}
+
+// LatencySnapshot summarizes observed latencies in milliseconds.
+type LatencySnapshot struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// SizeSnapshot summarizes observed body sizes in bytes.
+type SizeSnapshot struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// RouteSnapshot is a point-in-time view of a single route's metrics.
+type RouteSnapshot struct {
+	Count        int64           `json:"count"`
+	LatencyMs    LatencySnapshot `json:"latency_ms"`
+	RequestSize  SizeSnapshot    `json:"http_request_size_bytes"`
+	ResponseSize SizeSnapshot    `json:"http_response_size_bytes"`
+}
+
+// MetricsSnapshot is a point-in-time view of all collected metrics.
+type MetricsSnapshot struct {
+	RequestsTotal int64                    `json:"requests_total"`
+	Status        map[string]int64         `json:"status"`
+	Routes        map[string]RouteSnapshot `json:"routes"`
+}
+
+// NewMetrics creates an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		statusClasses: make(map[string]int64),
+		routes:        make(map[string]*routeMetrics),
+	}
+}
+
+// Observe records a completed request for the given route.
+func (m *Metrics) Observe(route string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.total++
+	m.statusClasses[fmt.Sprintf("%dxx", status/100)]++
+
+	rm := m.route(route)
+	rm.count++
+	rm.latencies.add(float64(duration) / float64(time.Millisecond))
+}
+
+// ObserveSizes records the request and response body sizes, in bytes, of a
+// completed request for the given route.
+func (m *Metrics) ObserveSizes(route string, requestBytes, responseBytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rm := m.route(route)
+	rm.requestSizes.add(float64(requestBytes))
+	rm.responseSizes.add(float64(responseBytes))
+}
+
+// route returns the metrics for route, creating them if needed. m.mu must be held.
+func (m *Metrics) route(route string) *routeMetrics {
+	rm, ok := m.routes[route]
+	if !ok {
+		rm = &routeMetrics{}
+		m.routes[route] = rm
+	}
+	return rm
+}
+
+// Snapshot returns a copy of the current metrics.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snap := MetricsSnapshot{
+		RequestsTotal: m.total,
+		Status:        make(map[string]int64, len(m.statusClasses)),
+		Routes:        make(map[string]RouteSnapshot, len(m.routes)),
+	}
+	for class, n := range m.statusClasses {
+		snap.Status[class] = n
+	}
+	for route, rm := range m.routes {
+		snap.Routes[route] = RouteSnapshot{
+			Count:        rm.count,
+			LatencyMs:    summarize(rm.latencies.samples),
+			RequestSize:  SizeSnapshot(summarize(rm.requestSizes.samples)),
+			ResponseSize: SizeSnapshot(summarize(rm.responseSizes.samples)),
+		}
+	}
+	return snap
+}
+
+// Handler returns an http.Handler that renders the metrics snapshot as JSON.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Snapshot())
+	})
+}
+
+// summarize computes latency percentiles from a set of samples.
+func summarize(samples []float64) LatencySnapshot {
+	if len(samples) == 0 {
+		return LatencySnapshot{}
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	return LatencySnapshot{
+		P50: percentile(sorted, 0.50),
+		P90: percentile(sorted, 0.90),
+		P99: percentile(sorted, 0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(p*float64(len(sorted)-1) + 0.5)
+	return sorted[idx]
+}
+
+// MetricsMiddleware records each request's route, status, latency, and body
+// sizes into m. Routes are labeled by their registered template (see
+// RouteTemplate). The request size is its Content-Length; when that is unknown,
+// as with chunked uploads, the bytes the handler read from the body are counted
+// instead. The response size is the number of body bytes the handler wrote.
+func MetricsMiddleware(m *Metrics) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			var body *countingBody
+			if r.ContentLength < 0 && r.Body != nil {
+				body = &countingBody{ReadCloser: r.Body}
+				r.Body = body
+			}
+			rec := responsewriter.New(w)
+			next.ServeHTTP(rec, r)
+
+			route := RouteTemplate(r)
+			m.Observe(route, rec.Status(), time.Since(start))
+			requestBytes := r.ContentLength
+			if body != nil {
+				requestBytes = body.n
+			}
+			m.ObserveSizes(route, requestBytes, rec.BytesWritten())
+		})
+	}
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// Metrics returns the server's metrics collector, creating it on first use.
+// It is safe to call from multiple goroutines.
+func (s *Server) Metrics() *Metrics {
+	s.metricsOnce.Do(func() { s.metrics = NewMetrics() })
+	return s.metrics
+}
+
+// MetricsJSONHandler returns an http.Handler exposing the server's metrics as JSON.
+// Register MetricsMiddleware(s.Metrics()) to feed it.
+func (s *Server) MetricsJSONHandler() http.Handler {
+	return s.Metrics().Handler()
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Use(MetricsMiddleware(srv.Metrics()))
+
+	srv.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	srv.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	for i := 0; i < 3; i++ {
+		srv.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	}
+	srv.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	w := httptest.NewRecorder()
+	srv.MetricsJSONHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %s", ct)
+	}
+
+	var snap MetricsSnapshot
+	if err := json.NewDecoder(w.Body).Decode(&snap); err != nil {
+		t.Fatalf("failed to decode metrics JSON: %v", err)
+	}
+
+	if snap.RequestsTotal != 4 {
+		t.Errorf("expected 4 total requests, got %d", snap.RequestsTotal)
+	}
+	if snap.Status["2xx"] != 3 {
+		t.Errorf("expected 3 2xx responses, got %d", snap.Status["2xx"])
+	}
+	if snap.Status["4xx"] != 1 {
+		t.Errorf("expected 1 4xx response, got %d", snap.Status["4xx"])
+	}
+
+	route, ok := snap.Routes["/ok"]
+	if !ok {
+		t.Fatal("expected metrics for /ok")
+	}
+	if route.Count != 3 {
+		t.Errorf("expected 3 requests to /ok, got %d", route.Count)
+	}
+	if route.LatencyMs.P50 <= 0 || route.LatencyMs.Max <= 0 {
+		t.Errorf("expected non-zero latency, got %+v", route.LatencyMs)
+	}
+}
+
+func TestMetricsMiddlewareUsesRouteTemplate(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Use(MetricsMiddleware(srv.Metrics()))
+	srv.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, id := range []string{"1", "2", "3"} {
+		srv.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/"+id, nil))
+	}
+
+	snap := srv.Metrics().Snapshot()
+	if len(snap.Routes) != 1 {
+		t.Fatalf("expected a single route label, got %v", snap.Routes)
+	}
+	if got := snap.Routes["/users/{id}"].Count; got != 3 {
+		t.Errorf("expected 3 requests for /users/{id}, got %d", got)
+	}
+}
+
+func TestMetricsMiddlewareSizes(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Use(MetricsMiddleware(srv.Metrics()))
+	srv.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte("stored"))
+	})
+
+	body := strings.Repeat("x", 1500)
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/upload", strings.NewReader(body)))
+
+	// A chunked upload has no Content-Length, so the bytes read are counted
+	req := httptest.NewRequest("POST", "/upload", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	route := srv.Metrics().Snapshot().Routes["/upload"]
+	if route.RequestSize.P50 != 1500 || route.RequestSize.Max != 1500 {
+		t.Errorf("expected request size 1500 bytes, got %+v", route.RequestSize)
+	}
+	if route.ResponseSize.Max != float64(len("stored")) {
+		t.Errorf("expected response size %d bytes, got %+v", len("stored"), route.ResponseSize)
+	}
+
+	var snap struct {
+		Routes map[string]map[string]interface{} `json:"routes"`
+	}
+	w := httptest.NewRecorder()
+	srv.MetricsJSONHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if err := json.NewDecoder(w.Body).Decode(&snap); err != nil {
+		t.Fatalf("failed to decode metrics JSON: %v", err)
+	}
+	for _, key := range []string{"http_request_size_bytes", "http_response_size_bytes"} {
+		if _, ok := snap.Routes["/upload"][key]; !ok {
+			t.Errorf("expected %s in metrics JSON", key)
+		}
+	}
+}
+
+func TestServerMetricsConcurrent(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+
+	var wg sync.WaitGroup
+	got := make([]*Metrics, 8)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = srv.Metrics()
+		}()
+	}
+	wg.Wait()
+
+	for _, m := range got {
+		if m == nil || m != got[0] {
+			t.Fatal("expected every caller to get the same collector")
+		}
+	}
+}
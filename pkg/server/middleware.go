@@ -0,0 +1,624 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+	"github.com/Waryway/Wayframe/pkg/server/responsewriter"
+)
+
+// Chain composes several middlewares into one, so a reusable stack can be defined
+// once and registered with a single Use call:
+//
+//	var apiStack = server.Chain(server.RequestIDMiddleware(), logging, recovery)
+//	srv.Use(apiStack)
+//
+// The resulting order matches calling Use with each middleware in turn: the first
+// middleware is the outermost wrapper and sees the request first.
+func Chain(mw ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mw) - 1; i >= 0; i-- {
+			next = mw[i](next)
+		}
+		return next
+	}
+}
+
+// TrailingSlashOptions configures StripTrailingSlashMiddleware.
+type TrailingSlashOptions struct {
+	// Rewrite serves the canonical path internally instead of redirecting.
+	Rewrite bool
+}
+
+// StripTrailingSlashMiddleware canonicalizes paths with a trailing slash, such as
+// /users/ to /users. The root path "/" is left untouched. By default clients are
+// redirected (301 for GET and HEAD, 308 otherwise so the method and body are kept);
+// with Rewrite set, the request is routed to the canonical path without a redirect.
+//
+// Register it with Server.Pre so it runs before routing; with Use it only sees
+// requests that already matched a pattern. Under Pre, a path is left alone when
+// its slash is needed to reach the route, as with a /static/ subtree or an SPA
+// mount, since ServeMux would otherwise redirect the canonical path straight back.
+func StripTrailingSlashMiddleware(opts ...TrailingSlashOptions) Middleware {
+	var o TrailingSlashOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if path == "/" || !strings.HasSuffix(path, "/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			canonical := "/" + strings.Trim(path, "/")
+			if s, ok := r.Context().Value(serverKey{}).(*Server); ok {
+				if p := s.patternFor(r, path); p != "" && p == s.patternFor(r, canonical) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if o.Rewrite {
+				r2 := new(http.Request)
+				*r2 = *r
+				u := *r.URL
+				u.Path = canonical
+				u.RawPath = ""
+				r2.URL = &u
+				next.ServeHTTP(w, r2)
+				return
+			}
+
+			target := canonical
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, target, status)
+		})
+	}
+}
+
+// CanonicalHostOptions configures CanonicalHostMiddleware.
+type CanonicalHostOptions struct {
+	// Scheme forces the scheme of redirect targets, such as "https". By default
+	// the request's own scheme is kept: the first X-Forwarded-Proto value when
+	// set by a proxy to "http" or "https", otherwise "https" for TLS requests
+	// and "http" for others.
+	Scheme string
+}
+
+// CanonicalHostMiddleware redirects requests whose Host differs from host,
+// such as www.example.com when host is example.com, to the same path and query
+// on host. Hosts are compared case-insensitively; include the port in host if
+// clients reach it on a non-default port. Like StripTrailingSlashMiddleware it
+// responds 301 for GET and HEAD and 308 otherwise, so the method and body are kept.
+//
+// Register it with Server.Pre so it also covers requests that match no route.
+func CanonicalHostMiddleware(host string, opts ...CanonicalHostOptions) Middleware {
+	var o CanonicalHostOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Host, host) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme := o.Scheme
+			if scheme == "" {
+				scheme = requestScheme(r)
+			}
+			target := scheme + "://" + host + r.URL.EscapedPath()
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, target, status)
+		})
+	}
+}
+
+// requestScheme returns the scheme the client used, preferring the first
+// X-Forwarded-Proto value set by a proxy in front of the server. Values other
+// than "http" and "https" are ignored, so they never reach a Location header.
+func requestScheme(r *http.Request) string {
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	switch proto = strings.ToLower(strings.TrimSpace(proto)); proto {
+	case "http", "https":
+		return proto
+	}
+	return connScheme(r)
+}
+
+// connScheme returns the scheme of the connection the request arrived on,
+// ignoring headers a client or proxy may have set.
+func connScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// RequestLimitsMiddleware rejects requests whose raw query string is longer
+// than maxQueryStringBytes, or that carry more than maxHeaderCount header
+// field values, with 431 Request Header Fields Too Large. A limit of 0 is not
+// enforced. Config.MaxQueryStringBytes and MaxHeaderCount install it ahead of
+// all other middleware; use it directly to apply limits to part of an app.
+func RequestLimitsMiddleware(maxQueryStringBytes, maxHeaderCount int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxQueryStringBytes > 0 && len(r.URL.RawQuery) > maxQueryStringBytes {
+				http.Error(w, "query string too large", http.StatusRequestHeaderFieldsTooLarge)
+				return
+			}
+			if maxHeaderCount > 0 {
+				count := 0
+				for _, values := range r.Header {
+					count += len(values)
+				}
+				if count > maxHeaderCount {
+					http.Error(w, "too many header fields", http.StatusRequestHeaderFieldsTooLarge)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DefaultMaxDecompressedBytes is the default limit on a decompressed request body.
+const DefaultMaxDecompressedBytes = 10 << 20
+
+// DecompressOptions configures DecompressMiddleware.
+type DecompressOptions struct {
+	// MaxBytes caps the decompressed body size to guard against decompression
+	// bombs. Reads beyond it fail with *http.MaxBytesError.
+	// Defaults to DefaultMaxDecompressedBytes.
+	MaxBytes int64
+}
+
+// DecompressMiddleware transparently decompresses request bodies sent with
+// Content-Encoding gzip or deflate. The Content-Encoding and Content-Length
+// headers are removed so handlers see a plain body. Malformed compressed bodies
+// are rejected with 400 Bad Request; other encodings are passed through untouched.
+func DecompressMiddleware(opts ...DecompressOptions) Middleware {
+	maxBytes := int64(DefaultMaxDecompressedBytes)
+	if len(opts) > 0 && opts[0].MaxBytes > 0 {
+		maxBytes = opts[0].MaxBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var decoded io.ReadCloser
+			var err error
+			switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+			case "gzip", "x-gzip":
+				decoded, err = gzip.NewReader(r.Body)
+			case "deflate":
+				decoded, err = zlib.NewReader(r.Body)
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Malformed compressed body", http.StatusBadRequest)
+				return
+			}
+
+			body := r.Body
+			r.Body = &decompressedBody{
+				Reader:  http.MaxBytesReader(w, decoded, maxBytes),
+				closers: []io.Closer{decoded, body},
+			}
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decompressedBody closes both the decompressor and the original body.
+type decompressedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	var first error
+	for _, c := range b.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// GzipMiddleware compresses response bodies with gzip for clients that accept
+// it, streaming the compressed output so flushed responses still reach the
+// client promptly. Responses without a body, and responses that already set a
+// Content-Encoding, are sent unchanged. A strong ETag set by an inner
+// middleware is made weak, since it describes the uncompressed body.
+//
+// Register GzipMiddleware before ETagMiddleware so the ETag is computed on the
+// uncompressed body:
+//
+//	srv.Use(server.GzipMiddleware())
+//	srv.Use(server.ETagMiddleware())
+func GzipMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Values("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := responsewriter.New(w)
+			var gz *gzip.Writer
+			rw.OnWriteHeader(func(status int) {
+				h := w.Header()
+				if !bodyAllowed(status) || r.Method == http.MethodHead || h.Get("Content-Encoding") != "" {
+					return
+				}
+				h.Set("Content-Encoding", "gzip")
+				h.Del("Content-Length")
+				if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+					h.Set("ETag", "W/"+etag)
+				}
+				gz = gzip.NewWriter(w)
+				rw.SetBodyWriter(gz)
+			})
+			defer func() {
+				if gz != nil {
+					gz.Close()
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(values []string) bool {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "gzip" && coding != "*" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// bodyAllowed reports whether a response with status may include a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// ETagMiddleware adds a strong ETag, derived from a hash of the body, to
+// successful GET responses that do not already have one, and responds with
+// 304 Not Modified when the request's If-None-Match header matches it.
+// Responses are buffered to compute the hash; if the handler flushes, the
+// response is streamed instead and no ETag is added.
+func ETagMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := responsewriter.NewBuffer(w)
+			next.ServeHTTP(buf, r)
+			if buf.Streaming() {
+				return
+			}
+			if buf.Status() != http.StatusOK || w.Header().Get("ETag") != "" {
+				buf.Send()
+				return
+			}
+
+			sum := sha256.Sum256(buf.Body())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			buf.Send()
+		})
+	}
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using the
+// weak comparison RFC 9110 requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadShedMiddleware rejects requests with 503 Service Unavailable and a
+// Retry-After header when more than maxInFlight requests are already being
+// handled, rather than queueing them. Requests whose path exactly matches one of
+// exemptPaths (such as health checks) are always served and not counted. It
+// panics if maxInFlight is not positive.
+func LoadShedMiddleware(maxInFlight int, exemptPaths ...string) Middleware {
+	if maxInFlight <= 0 {
+		panic(fmt.Sprintf("server: invalid load shedding limit %d; it must be positive", maxInFlight))
+	}
+	sem := make(chan struct{}, maxInFlight)
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, p := range exemptPaths {
+		exempt[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				next.ServeHTTP(w, r)
+			default:
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+// PerClientConcurrencyMiddleware rejects requests with 429 Too Many Requests
+// when the client, identified by the host part of its remote address, already
+// has max requests in flight. It stops a single client from tying up the
+// server with many slow requests. A client's slot is released when its
+// handler returns, and clients with no requests in flight are forgotten. It
+// panics if max is not positive, since such a limit would reject every request.
+func PerClientConcurrencyMiddleware(max int) Middleware {
+	if max <= 0 {
+		panic(fmt.Sprintf("server: invalid per-client concurrency limit %d; it must be positive", max))
+	}
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := clientIP(r)
+
+			mu.Lock()
+			if inFlight[key] >= max {
+				mu.Unlock()
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+			inFlight[key]++
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				if inFlight[key]--; inFlight[key] <= 0 {
+					delete(inFlight, key)
+				}
+				mu.Unlock()
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireHeadersMiddleware rejects requests that lack the headers in
+// required, keyed by header name. A request missing a header gets 400 Bad
+// Request. If a header's required value is non-empty, the request's value must
+// match it exactly, or the request gets 403 Forbidden; an empty value only
+// requires the header to be present. Values are compared in constant time, so
+// the middleware can guard internal endpoints with a shared token:
+//
+//	srv.Use(server.RequireHeadersMiddleware(map[string]string{
+//	    "X-Internal-Token": token,
+//	    "X-Tenant":         "",
+//	}))
+//
+// Since the comparison is exact, it is not suited to headers with parameters,
+// such as Content-Type, where "application/json; charset=utf-8" would not
+// match "application/json".
+func RequireHeadersMiddleware(required map[string]string) Middleware {
+	// Check in a fixed order so the same request always gets the same error
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, name := range names {
+				values := r.Header.Values(name)
+				if len(values) == 0 {
+					http.Error(w, "missing required header "+http.CanonicalHeaderKey(name), http.StatusBadRequest)
+					return
+				}
+				want := required[name]
+				if want != "" && subtle.ConstantTimeCompare([]byte(values[0]), []byte(want)) != 1 {
+					http.Error(w, "invalid "+http.CanonicalHeaderKey(name)+" header", http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Rate limit headers set by RateLimitMiddleware on every response.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitMiddleware limits each client, identified by the host part of its
+// remote address, to limit requests per window with a token bucket: a client
+// may burst up to limit requests, and its quota refills evenly over window.
+// Requests over the limit are rejected with 429 Too Many Requests and a
+// Retry-After header.
+//
+// Every response, not only rejections, reports the client's quota so clients
+// can pace themselves: X-RateLimit-Limit is limit, X-RateLimit-Remaining is
+// the number of requests left after this one, and X-RateLimit-Reset is the
+// number of seconds until the quota is full again. Clients whose quota has
+// been full for a window are forgotten. It panics if limit or window is not
+// positive.
+func RateLimitMiddleware(limit int, window time.Duration) Middleware {
+	if limit <= 0 || window <= 0 {
+		panic(fmt.Sprintf("server: invalid rate limit of %d requests per %v; both must be positive", limit, window))
+	}
+	capacity := float64(limit)
+	perSecond := capacity / window.Seconds()
+
+	var mu sync.Mutex
+	buckets := make(map[string]*tokenBucket)
+	lastSweep := time.Now()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := clientIP(r)
+			now := time.Now()
+
+			mu.Lock()
+			if now.Sub(lastSweep) >= window {
+				for k, b := range buckets {
+					if now.Sub(b.last) >= window {
+						delete(buckets, k)
+					}
+				}
+				lastSweep = now
+			}
+			b, ok := buckets[key]
+			if !ok {
+				b = &tokenBucket{tokens: capacity, last: now}
+				buckets[key] = b
+			}
+			b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+			b.last = now
+			allowed := b.tokens >= 1
+			if allowed {
+				b.tokens--
+			}
+			tokens := b.tokens
+			mu.Unlock()
+
+			h := w.Header()
+			h.Set(RateLimitLimitHeader, strconv.Itoa(limit))
+			h.Set(RateLimitRemainingHeader, strconv.Itoa(int(tokens)))
+			h.Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds((capacity-tokens)/perSecond)))
+			if !allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds((1-tokens)/perSecond)))
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tokenBucket is one client's quota for RateLimitMiddleware.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ceilSeconds rounds a number of seconds up to a whole number.
+func ceilSeconds(seconds float64) int {
+	return int(math.Ceil(seconds))
+}
+
+// TimeoutMiddleware limits the wrapped handlers to d. The request context is
+// cancelled at the deadline, and if the handler has not finished by then the
+// client receives 503 Service Unavailable. It is built on http.TimeoutHandler,
+// so responses are buffered and cannot be streamed with Flush.
+//
+// Timeouts nest: when a route registered with Server.HandleWithTimeout also
+// runs under a global TimeoutMiddleware added with Use, the tighter of the two
+// applies.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, "request timed out")
+	}
+}
+
+// BodyTimeoutMiddleware gives the wrapped handlers d to read the request body,
+// overriding the server's ReadTimeout for those routes only. Use it on upload
+// endpoints that need a longer read window without loosening the global limit:
+//
+//	srv.Handle("POST /upload", server.BodyTimeoutMiddleware(5*time.Minute)(uploadHandler))
+//
+// The deadline is set with http.ResponseController when the handler starts.
+// If the ResponseWriter does not support read deadlines, the server's
+// ReadTimeout still applies.
+func BodyTimeoutMiddleware(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NewResponseController(w).SetReadDeadline(time.Now().Add(d))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoggerMiddleware stores log in each request's context with logger.IntoContext,
+// so handlers and the libraries they call can retrieve it with logger.FromContext.
+// If RequestIDMiddleware ran first, the stored logger carries a request_id field.
+func LoggerMiddleware(log *logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqLog := log
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				reqLog = log.WithField(requestIDField, id)
+			}
+			next.ServeHTTP(w, r.WithContext(logger.IntoContext(r.Context(), reqLog)))
+		})
+	}
+}
@@ -0,0 +1,678 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+func TestStripTrailingSlashRedirect(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Pre(StripTrailingSlashMiddleware())
+	srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "users")
+	})
+	srv.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "root")
+	})
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/?page=2", nil))
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("expected status 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/users?page=2" {
+		t.Errorf("expected redirect to /users?page=2, got %s", loc)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "root" {
+		t.Errorf("expected root to be untouched, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Leading slashes must not produce a protocol-relative redirect
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "//evil.example.com/", nil))
+	if loc := w.Header().Get("Location"); loc != "/evil.example.com" {
+		t.Errorf("expected redirect to stay on host, got %s", loc)
+	}
+}
+
+func TestStripTrailingSlashRewrite(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Pre(StripTrailingSlashMiddleware(TrailingSlashOptions{Rewrite: true}))
+	srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	})
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "/users" {
+		t.Errorf("expected handler to see /users, got %q", w.Body.String())
+	}
+}
+
+func TestStripTrailingSlashKeepsSubtreePatterns(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rewrite := range []bool{false, true} {
+		srv := New(Config{Addr: ":0"})
+		srv.Pre(StripTrailingSlashMiddleware(TrailingSlashOptions{Rewrite: rewrite}))
+		srv.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "static "+r.URL.Path)
+		})
+		srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "users")
+		})
+		srv.SPA("/app", dir)
+
+		for path, want := range map[string]string{
+			"/static/":         "static /static/",
+			"/static/css/app/": "static /static/css/app/",
+			"/app/":            "index",
+		} {
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != http.StatusOK || w.Body.String() != want {
+				t.Errorf("rewrite=%v %s: expected 200 %q, got %d %q (Location %q)",
+					rewrite, path, want, w.Code, w.Body.String(), w.Header().Get("Location"))
+			}
+		}
+
+		// A slash that no pattern needs is still stripped
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+		if rewrite {
+			if w.Code != http.StatusOK || w.Body.String() != "users" {
+				t.Errorf("expected /users/ to be rewritten, got %d %q", w.Code, w.Body.String())
+			}
+		} else if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/users" {
+			t.Errorf("expected /users/ to redirect to /users, got %d %q", w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestCanonicalHostMiddleware(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Pre(CanonicalHostMiddleware("example.com"))
+	srv.HandleFunc("/docs/intro", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "intro")
+	})
+
+	req := httptest.NewRequest("GET", "/docs/intro?lang=en", nil)
+	req.Host = "www.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("expected status 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "https://example.com/docs/intro?lang=en" {
+		t.Errorf("expected redirect to https://example.com/docs/intro?lang=en, got %s", loc)
+	}
+
+	req = httptest.NewRequest("POST", "/docs/intro", nil)
+	req.Host = "www.example.com"
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "http://example.com/docs/intro" {
+		t.Errorf("expected 308 to http://example.com/docs/intro, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest("GET", "/docs/intro", nil)
+	req.Host = "Example.com"
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "intro" {
+		t.Errorf("expected canonical host to pass through, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestCanonicalHostMiddlewareScheme(t *testing.T) {
+	handler := CanonicalHostMiddleware("example.com", CanonicalHostOptions{Scheme: "https"})(http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "old.example.org"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if loc := w.Header().Get("Location"); loc != "https://example.com/" {
+		t.Errorf("expected redirect to https://example.com/, got %s", loc)
+	}
+}
+
+func TestCanonicalHostMiddlewareBogusForwardedProto(t *testing.T) {
+	handler := CanonicalHostMiddleware("example.com")(http.NotFoundHandler())
+
+	for _, proto := range []string{"javascript", "ftp", "https:"} {
+		req := httptest.NewRequest("GET", "/docs", nil)
+		req.Host = "www.example.com"
+		req.Header.Set("X-Forwarded-Proto", proto)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if loc := w.Header().Get("Location"); loc != "http://example.com/docs" {
+			t.Errorf("X-Forwarded-Proto %q: expected redirect to http://example.com/docs, got %s", proto, loc)
+		}
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	srv := New(Config{Addr: ":0", MaxQueryStringBytes: 64, MaxHeaderCount: 5})
+	srv.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/search?q="+strings.Repeat("a", 100), nil))
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected 431 for an oversized query string, got %d", w.Code)
+	}
+
+	// Limits apply before routing, so unknown paths are rejected too
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/missing?q="+strings.Repeat("a", 100), nil))
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected 431 before routing, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/search?q=go", nil)
+	for i := 0; i < 6; i++ {
+		req.Header.Add("X-Tag", fmt.Sprint(i))
+	}
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("expected 431 for too many header fields, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/search?q=go", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("expected a request within limits to pass, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+"-before")
+				next.ServeHTTP(w, r)
+				order = append(order, name+"-after")
+			})
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}
+
+	individual := New(Config{Addr: ":0"})
+	individual.Use(tag("a"))
+	individual.Use(tag("b"))
+	individual.Use(tag("c"))
+	individual.HandleFunc("/test", handler)
+	individual.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+	expected := order
+
+	order = nil
+	chained := New(Config{Addr: ":0"})
+	chained.Use(Chain(tag("a"), tag("b"), tag("c")))
+	chained.HandleFunc("/test", handler)
+	chained.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("expected chained order %v, got %v", expected, order)
+	}
+	if order[0] != "a-before" || order[len(order)-1] != "a-after" {
+		t.Errorf("expected first middleware to be outermost, got %v", order)
+	}
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"name":"wayframe"}`))
+	zw.Close()
+
+	var body string
+	var encoding string
+	handler := DecompressMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		body = string(b)
+		encoding = r.Header.Get("Content-Encoding")
+	}))
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if body != `{"name":"wayframe"}` {
+		t.Errorf("expected decompressed body, got %q", body)
+	}
+	if encoding != "" {
+		t.Errorf("expected Content-Encoding to be removed, got %q", encoding)
+	}
+}
+
+func TestDecompressMiddlewareLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte("a"), 1<<20))
+	zw.Close()
+
+	var readErr error
+	handler := DecompressMiddleware(DecompressOptions{MaxBytes: 1024})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var maxErr *http.MaxBytesError
+	if !errors.As(readErr, &maxErr) {
+		t.Errorf("expected MaxBytesError for oversized body, got %v", readErr)
+	}
+}
+
+func TestDecompressMiddlewareMalformed(t *testing.T) {
+	handler := DecompressMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called for malformed body")
+	}))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestLoadShedMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	handler := LoadShedMiddleware(2, "/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+		}()
+	}
+	<-started
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/other", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 when saturated, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header on shed request")
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected exempt path to be served, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/other", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected request to succeed after slots free up, got %d", w.Code)
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithHandler(slog.NewTextHandler(&buf, nil)).WithField("app", "test")
+
+	handler := LoggerMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("from handler")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if out := buf.String(); !strings.Contains(out, "from handler") || !strings.Contains(out, "app=test") {
+		t.Errorf("expected handler to log through the context logger, got %q", out)
+	}
+}
+
+func TestBodyTimeoutMiddleware(t *testing.T) {
+	readBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/default", readBody)
+	mux.Handle("/upload", BodyTimeoutMiddleware(5*time.Second)(readBody))
+
+	ts := httptest.NewUnstartedServer(mux)
+	ts.Config.ReadTimeout = 200 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	// slowPost sends the body in two parts with a pause longer than ReadTimeout
+	slowPost := func(path string) (int, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.Write([]byte("part one,"))
+			time.Sleep(500 * time.Millisecond)
+			pw.Write([]byte("part two"))
+			pw.Close()
+		}()
+		resp, err := http.Post(ts.URL+path, "text/plain", pr)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	if status, err := slowPost("/upload"); err != nil || status != http.StatusOK {
+		t.Errorf("expected extended timeout to succeed, got status %d, err %v", status, err)
+	}
+	if status, err := slowPost("/default"); err == nil && status == http.StatusOK {
+		t.Error("expected default ReadTimeout to fail the slow upload")
+	}
+}
+
+func TestPerClientConcurrencyMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	handler := PerClientConcurrencyMiddleware(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+	}))
+
+	request := func(path, remoteAddr string) *http.Request {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), request("/slow", fmt.Sprintf("203.0.113.7:%d", 40000+port)))
+		}(i)
+	}
+	<-started
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request("/fast", "203.0.113.7:40002"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 for a client at its limit, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request("/fast", "198.51.100.1:40000"))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected another client to be served, got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request("/fast", "203.0.113.7:40003"))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected client to be served after its requests complete, got %d", w.Code)
+	}
+}
+
+func TestRequireHeadersMiddleware(t *testing.T) {
+	handler := RequireHeadersMiddleware(map[string]string{
+		"X-Internal-Token": "s3cret",
+		"X-Tenant":         "",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"all present", map[string]string{"X-Internal-Token": "s3cret", "X-Tenant": "acme"}, http.StatusOK},
+		{"missing header", map[string]string{"X-Internal-Token": "s3cret"}, http.StatusBadRequest},
+		{"wrong value", map[string]string{"X-Internal-Token": "guess", "X-Tenant": "acme"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/internal/sync", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), "X-Tenant") {
+				t.Errorf("expected the error to name the missing header, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	handler := RateLimitMiddleware(3, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, want := range []string{"2", "1", "0"} {
+		w := request("203.0.113.7:40000")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200 under the limit, got %d", i+1, w.Code)
+		}
+		if got := w.Header().Get(RateLimitLimitHeader); got != "3" {
+			t.Errorf("request %d: expected limit 3, got %q", i+1, got)
+		}
+		if got := w.Header().Get(RateLimitRemainingHeader); got != want {
+			t.Errorf("request %d: expected remaining %s, got %q", i+1, want, got)
+		}
+	}
+
+	w := request("203.0.113.7:40001")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 over the limit, got %d", w.Code)
+	}
+	if w.Header().Get(RateLimitRemainingHeader) != "0" || w.Header().Get("Retry-After") != "20" {
+		t.Errorf("expected remaining 0 and Retry-After 20, got %q and %q",
+			w.Header().Get(RateLimitRemainingHeader), w.Header().Get("Retry-After"))
+	}
+	if got := w.Header().Get(RateLimitResetHeader); got != "60" {
+		t.Errorf("expected reset in 60 seconds for an empty bucket, got %q", got)
+	}
+
+	w = request("198.51.100.1:40000")
+	if w.Code != http.StatusOK || w.Header().Get(RateLimitRemainingHeader) != "2" {
+		t.Errorf("expected another client to have its own quota, got %d with remaining %q",
+			w.Code, w.Header().Get(RateLimitRemainingHeader))
+	}
+}
+
+// expectPanic fails the test unless fn panics with a message containing want.
+func expectPanic(t *testing.T, want string, fn func()) {
+	t.Helper()
+	defer func() {
+		if msg := fmt.Sprint(recover()); !strings.Contains(msg, want) {
+			t.Errorf("expected a panic containing %q, got: %s", want, msg)
+		}
+	}()
+	fn()
+}
+
+func TestRateLimitMiddlewareInvalid(t *testing.T) {
+	expectPanic(t, "invalid rate limit", func() { RateLimitMiddleware(0, time.Minute) })
+	expectPanic(t, "invalid rate limit", func() { RateLimitMiddleware(10, 0) })
+}
+
+func TestLoadShedMiddlewareInvalid(t *testing.T) {
+	expectPanic(t, "invalid load shedding limit", func() { LoadShedMiddleware(0) })
+	expectPanic(t, "invalid load shedding limit", func() { LoadShedMiddleware(-1) })
+}
+
+func TestPerClientConcurrencyMiddlewareInvalid(t *testing.T) {
+	expectPanic(t, "invalid per-client concurrency limit", func() { PerClientConcurrencyMiddleware(0) })
+}
+
+func TestETagWithGzip(t *testing.T) {
+	body := strings.Repeat("wayframe ", 100)
+	srv := New(Config{Addr: ":0"})
+	srv.Use(GzipMiddleware())
+	srv.Use(ETagMiddleware())
+	srv.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(body))
+	})
+
+	req := httptest.NewRequest("GET", "/page", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if ce := w.Header().Get("Content-Encoding"); ce != "gzip" {
+		t.Fatalf("expected Content-Encoding gzip, got %q", ce)
+	}
+	sum := sha256.Sum256([]byte(body))
+	want := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	etag := w.Header().Get("ETag")
+	if etag != want {
+		t.Errorf("expected weak ETag of the uncompressed body %s, got %s", want, etag)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("expected gzip body: %v", err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil || string(decoded) != body {
+		t.Errorf("expected decompressed body to match, got %q (err %v)", decoded, err)
+	}
+
+	req = httptest.NewRequest("GET", "/page", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching If-None-Match, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Errorf("expected 304 without an encoded body, got %q with %d bytes", w.Header().Get("Content-Encoding"), w.Body.Len())
+	}
+}
+
+func TestGzipMiddlewareSkipsUnacceptedEncoding(t *testing.T) {
+	handler := GzipMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	}))
+
+	for _, accept := range []string{"", "identity", "gzip;q=0"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "plain" {
+			t.Errorf("Accept-Encoding %q: expected uncompressed body, got %q", accept, w.Body.String())
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: expected Vary: Accept-Encoding", accept)
+		}
+	}
+}
+
+func TestGzipMiddlewareStreams(t *testing.T) {
+	flushed := make(chan struct{})
+	handler := GzipMiddleware()(ETagMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first"))
+		http.NewResponseController(w).Flush()
+		close(flushed)
+		w.Write([]byte("second"))
+	})))
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	<-flushed
+
+	if resp.Header.Get("ETag") != "" {
+		t.Error("expected no ETag on a streamed response")
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("expected gzip body: %v", err)
+	}
+	decoded, _ := io.ReadAll(zr)
+	if string(decoded) != "firstsecond" {
+		t.Errorf("expected streamed body, got %q", decoded)
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the offer that best matches the request's Accept header,
+// respecting q-values and preferring more specific media ranges. Ties go to
+// the earlier offer. If the header is missing or matches no offer, the first
+// offer is returned as the default. It returns "" if there are no offers.
+//
+//	switch server.Negotiate(r, "application/json", "application/xml") {
+//	case "application/xml":
+//	    server.WriteXML(w, http.StatusOK, v)
+//	default:
+//	    server.WriteJSON(w, http.StatusOK, v)
+//	}
+func Negotiate(r *http.Request, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	ranges := parseAccept(r.Header.Values("Accept"))
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses Accept header values into media ranges.
+func parseAccept(values []string) []mediaRange {
+	var ranges []mediaRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, _ := strings.Cut(part, ";")
+			typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+			if !ok || typ == "" || subtype == "" {
+				continue
+			}
+
+			q := 1.0
+			for _, param := range strings.Split(params, ";") {
+				name, val, _ := strings.Cut(param, "=")
+				if strings.TrimSpace(name) == "q" {
+					if parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil && parsed >= 0 && parsed <= 1 {
+						q = parsed
+					}
+				}
+			}
+			ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+	return ranges
+}
+
+// acceptQuality returns the q-value of the most specific range matching offer,
+// or 0 if no range matches.
+func acceptQuality(ranges []mediaRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+
+	q, specificity := 0.0, -1
+	for _, mr := range ranges {
+		var s int
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+	return q
+}
+
+// WriteJSON writes v as a JSON response with the given status code.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// WriteXML writes v as an XML response, including the XML header, with the given status code.
+func WriteXML(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// JSONHandler adapts a function returning a value and an error into an
+// http.Handler. On success the value is written with WriteJSON and a 200
+// status; on failure the error is written with WriteError:
+//
+//	srv.Handle("GET /users/{id}", server.JSONHandler(func(r *http.Request) (interface{}, error) {
+//	    user, ok := users[r.PathValue("id")]
+//	    if !ok {
+//	        return nil, server.NewError(http.StatusNotFound, "user not found")
+//	    }
+//	    return user, nil
+//	}))
+func JSONHandler(fn func(r *http.Request) (interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := fn(r)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, v)
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/xml"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"specific", "application/xml", "application/xml"},
+		{"wildcard", "*/*", "application/json"},
+		{"subtype wildcard", "text/html, application/*", "application/json"},
+		{"q-value ordering", "application/json;q=0.5, application/xml;q=0.9", "application/xml"},
+		{"specific range overrides wildcard", "application/*;q=0.8, application/json;q=0.1", "application/xml"},
+		{"no accept header", "", "application/json"},
+		{"no match", "text/html", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if got := Negotiate(req, offers...); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := WriteJSON(w, http.StatusCreated, map[string]string{"id": "42"}); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %s", ct)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != `{"id":"42"}` {
+		t.Errorf("expected JSON body, got %s", body)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	type user struct {
+		ID string `xml:"id"`
+	}
+
+	w := httptest.NewRecorder()
+	if err := WriteXML(w, http.StatusOK, user{ID: "42"}); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("expected Content-Type application/xml, got %s", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<user><id>42</id></user>") {
+		t.Errorf("expected XML body, got %s", body)
+	}
+}
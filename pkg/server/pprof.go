@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// EnablePprof registers the net/http/pprof handlers on the server under
+// pathPrefix (default "/debug/pprof"), with mw applied in order around each
+// of them. Use it to put profiling behind authentication:
+//
+//	srv.EnablePprof("/debug/pprof", basicAuth)
+//
+// The handlers are registered on the server's own mux only; the server never
+// serves http.DefaultServeMux, where the pprof package also registers itself.
+func (s *Server) EnablePprof(pathPrefix string, mw ...Middleware) {
+	prefix := strings.TrimSuffix(pathPrefix, "/")
+	if prefix == "" {
+		prefix = "/debug/pprof"
+	}
+	protect := Chain(mw...)
+
+	// pprof.Index only resolves named profiles under /debug/pprof/, so
+	// dispatch them here to support any prefix.
+	s.Handle(prefix+"/", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := strings.TrimPrefix(r.URL.Path, prefix+"/"); name != "" {
+			pprof.Handler(name).ServeHTTP(w, r)
+			return
+		}
+		pprof.Index(w, r)
+	})))
+	s.Handle(prefix+"/cmdline", protect(http.HandlerFunc(pprof.Cmdline)))
+	s.Handle(prefix+"/profile", protect(http.HandlerFunc(pprof.Profile)))
+	s.Handle(prefix+"/symbol", protect(http.HandlerFunc(pprof.Symbol)))
+	s.Handle(prefix+"/trace", protect(http.HandlerFunc(pprof.Trace)))
+}
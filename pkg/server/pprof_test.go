@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func basicAuthMiddleware(user, pass string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, p, ok := r.BasicAuth()
+			if !ok || u != user || p != pass {
+				w.Header().Set("WWW-Authenticate", `Basic realm="pprof"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestEnablePprof(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.EnablePprof("/debug/pprof", basicAuthMiddleware("admin", "secret"))
+
+	tests := []struct {
+		name   string
+		path   string
+		auth   bool
+		status int
+	}{
+		{"index without auth", "/debug/pprof/", false, http.StatusUnauthorized},
+		{"index with auth", "/debug/pprof/", true, http.StatusOK},
+		{"named profile", "/debug/pprof/heap", true, http.StatusOK},
+		{"cmdline", "/debug/pprof/cmdline", true, http.StatusOK},
+		{"cmdline without auth", "/debug/pprof/cmdline", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.auth {
+				req.SetBasicAuth("admin", "secret")
+			}
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestEnablePprofCustomPrefix(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.EnablePprof("/internal/profiling/")
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/internal/profiling/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf("expected pprof index under custom prefix, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/internal/profiling/goroutine?debug=1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("expected goroutine profile under custom prefix, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected default prefix to be unregistered, got %d", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxyOptions configures ReverseProxy.
+type ProxyOptions struct {
+	// StripPrefix is removed from the request path before it is forwarded,
+	// so a proxy mounted at "/billing/" can forward /billing/invoices to the
+	// backend's /invoices. It is only removed at a path segment boundary:
+	// /billingfoo/x is forwarded unchanged.
+	StripPrefix string
+	// PreserveHost forwards the client's Host header instead of the target's.
+	PreserveHost bool
+	// TrustForwardedHeaders keeps the X-Forwarded-For, X-Forwarded-Host, and
+	// X-Forwarded-Proto values of incoming requests, appending the client
+	// address to X-Forwarded-For. Set it only when every request arrives
+	// through a proxy that sets them; otherwise clients could claim any
+	// address or scheme, so they are replaced with values describing the
+	// connection this server received.
+	TrustForwardedHeaders bool
+	// DialTimeout limits connecting to the target. Defaults to 10s.
+	DialTimeout time.Duration
+	// ResponseHeaderTimeout limits waiting for the target's response headers
+	// after the request is sent. Defaults to 30s.
+	ResponseHeaderTimeout time.Duration
+	// Transport sends requests to the target. It overrides DialTimeout and
+	// ResponseHeaderTimeout when set.
+	Transport http.RoundTripper
+	// ErrorLog receives errors reaching the target. If nil, they are logged
+	// with the log package's standard logger, as httputil.ReverseProxy does.
+	ErrorLog *log.Logger
+}
+
+// statusClientClosedRequest is the non-standard status nginx records when the
+// client goes away before the response, so access logs do not show the
+// request as served.
+const statusClientClosedRequest = 499
+
+// ReverseProxy returns a handler that forwards requests to target, a base URL
+// such as "http://billing.internal:8080". It is built on
+// httputil.NewSingleHostReverseProxy, so the target's path is joined with the
+// request path. X-Forwarded-For, X-Forwarded-Host, and X-Forwarded-Proto
+// describe the original request (see TrustForwardedHeaders), and the Host
+// header is set to the target's unless PreserveHost is set. If the target cannot be reached, the error is
+// logged to ErrorLog and the client receives a 502 Bad Gateway rendered by
+// WriteError. If the client cancels the request first, nothing is logged and
+// the response status is 499.
+//
+// Register it like any other handler so middleware such as logging and
+// recovery applies:
+//
+//	srv.Handle("/billing/", server.ReverseProxy("http://billing.internal:8080",
+//	    server.ProxyOptions{StripPrefix: "/billing"}))
+//
+// ReverseProxy panics if target is not an absolute URL.
+func ReverseProxy(target string, opts ProxyOptions) http.Handler {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		panic(fmt.Sprintf("server: invalid proxy target %q", target))
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 10 * time.Second
+	}
+	if opts.ResponseHeaderTimeout <= 0 {
+		opts.ResponseHeaderTimeout = 30 * time.Second
+	}
+
+	prefix := strings.TrimRight(opts.StripPrefix, "/")
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		if opts.TrustForwardedHeaders {
+			if r.Header.Get("X-Forwarded-Host") == "" {
+				r.Header.Set("X-Forwarded-Host", r.Host)
+			}
+			r.Header.Set("X-Forwarded-Proto", requestScheme(r))
+		} else {
+			// httputil appends the client address to any incoming value
+			r.Header.Del("X-Forwarded-For")
+			r.Header.Set("X-Forwarded-Host", r.Host)
+			r.Header.Set("X-Forwarded-Proto", connScheme(r))
+		}
+		if rest, ok := strings.CutPrefix(r.URL.Path, prefix); ok && prefix != "" && (rest == "" || rest[0] == '/') {
+			r.URL.Path = "/" + strings.TrimLeft(rest, "/")
+			r.URL.RawPath = ""
+		}
+		director(r)
+		if !opts.PreserveHost {
+			r.Host = u.Host
+		}
+	}
+
+	proxy.Transport = opts.Transport
+	if proxy.Transport == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{Timeout: opts.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+		proxy.Transport = transport
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+			w.WriteHeader(statusClientClosedRequest)
+			return
+		}
+		logf := log.Printf
+		if opts.ErrorLog != nil {
+			logf = opts.ErrorLog.Printf
+		}
+		logf("server: proxy error for %s %s: %v", r.Method, r.URL.Path, err)
+		WriteError(w, NewError(http.StatusBadGateway, http.StatusText(http.StatusBadGateway)))
+	}
+	return proxy
+}
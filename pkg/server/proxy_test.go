@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReverseProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend", "billing")
+		fmt.Fprintf(w, "path=%s host=%s xff=%s proto=%s fwdhost=%s",
+			r.URL.Path, r.Host, r.Header.Get("X-Forwarded-For"),
+			r.Header.Get("X-Forwarded-Proto"), r.Header.Get("X-Forwarded-Host"))
+	}))
+	defer backend.Close()
+
+	var logged []string
+	srv := New(Config{Addr: ":0"})
+	srv.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logged = append(logged, r.URL.Path)
+			next.ServeHTTP(w, r)
+		})
+	})
+	srv.Handle("/billing/", ReverseProxy(backend.URL+"/api", ProxyOptions{StripPrefix: "/billing"}))
+
+	req := httptest.NewRequest("GET", "http://app.example.com/billing/invoices", nil)
+	req.RemoteAddr = "203.0.113.7:40000"
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("X-Backend") != "billing" {
+		t.Fatalf("expected the backend's response, got %d %q", w.Code, w.Body.String())
+	}
+	backendHost := strings.TrimPrefix(backend.URL, "http://")
+	want := "path=/api/invoices host=" + backendHost + " xff=203.0.113.7 proto=http fwdhost=app.example.com"
+	if w.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, w.Body.String())
+	}
+	if len(logged) != 1 || logged[0] != "/billing/invoices" {
+		t.Errorf("expected middleware to see the proxied request, got %v", logged)
+	}
+}
+
+func TestReverseProxyStripPrefixBoundary(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer backend.Close()
+
+	proxy := ReverseProxy(backend.URL, ProxyOptions{StripPrefix: "/billing"})
+	for path, want := range map[string]string{
+		"/billing":        "/",
+		"/billing/":       "/",
+		"/billing/x":      "/x",
+		"/billingfoo/x":   "/billingfoo/x",
+		"/other/billing/": "/other/billing/",
+	} {
+		w := httptest.NewRecorder()
+		proxy.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: expected the backend to see %s, got %s", path, want, w.Body.String())
+		}
+	}
+}
+
+func TestReverseProxyForwardedHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "xff=%s proto=%s fwdhost=%s", r.Header.Get("X-Forwarded-For"),
+			r.Header.Get("X-Forwarded-Proto"), r.Header.Get("X-Forwarded-Host"))
+	}))
+	defer backend.Close()
+
+	spoofed := func() *http.Request {
+		req := httptest.NewRequest("GET", "http://app.example.com/", nil)
+		req.RemoteAddr = "203.0.113.7:40000"
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "evil.example.com")
+		return req
+	}
+
+	// By default the headers describe the connection, not what the client claims
+	w := httptest.NewRecorder()
+	ReverseProxy(backend.URL, ProxyOptions{}).ServeHTTP(w, spoofed())
+	if want := "xff=203.0.113.7 proto=http fwdhost=app.example.com"; w.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	ReverseProxy(backend.URL, ProxyOptions{TrustForwardedHeaders: true}).ServeHTTP(w, spoofed())
+	if want := "xff=10.0.0.1, 203.0.113.7 proto=https fwdhost=evil.example.com"; w.Body.String() != want {
+		t.Errorf("expected trusted headers %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestReverseProxyBadGateway(t *testing.T) {
+	// Reserve a port and close it so nothing is listening
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	errorLog := &bytes.Buffer{}
+	handler := ReverseProxy("http://"+addr, ProxyOptions{ErrorLog: log.New(errorLog, "", 0)})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/orders", nil))
+
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusBadGateway || !strings.Contains(string(body), `"code":502`) {
+		t.Errorf("expected a 502 JSON error, got %d %s", w.Code, body)
+	}
+	if !strings.Contains(errorLog.String(), "GET /orders") || !strings.Contains(errorLog.String(), "connection refused") {
+		t.Errorf("expected the upstream error to be logged, got: %s", errorLog.String())
+	}
+}
+
+func TestReverseProxyClientCancel(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	errorLog := &bytes.Buffer{}
+	handler := ReverseProxy(upstream.URL, ProxyOptions{ErrorLog: log.New(errorLog, "", 0)})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(50*time.Millisecond, cancel)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	if w.Code != statusClientClosedRequest {
+		t.Errorf("expected status 499 for a client cancellation, got %d", w.Code)
+	}
+	if errorLog.Len() != 0 {
+		t.Errorf("expected nothing logged for a client cancellation, got: %s", errorLog.String())
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+const (
+	// RequestIDHeader is the header used to carry the request ID.
+	RequestIDHeader = "X-Request-ID"
+	// CorrelationIDHeader is the header used to carry the correlation ID across services.
+	CorrelationIDHeader = "X-Correlation-ID"
+
+	// maxIDLength bounds IDs accepted from clients.
+	maxIDLength = 128
+
+	// requestIDField is the log field that carries the request ID.
+	requestIDField = "request_id"
+)
+
+// IDGenerator generates request and correlation IDs. It defaults to 16 random
+// bytes encoded as hex and can be replaced, for example with a ULID generator.
+// Set it during initialization, before the server starts handling requests.
+var IDGenerator func() string = randomHexID
+
+// randomHexID returns 16 bytes from crypto/rand encoded as hex.
+func randomHexID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	correlationIDKey
+)
+
+// RequestIDMiddleware assigns each request an ID, reusing a valid incoming
+// X-Request-ID header or generating one with IDGenerator. The ID is echoed in the
+// response header and stored in the request context (see RequestIDFromContext).
+// The context logger (see LoggerMiddleware) is also given a request_id field, so
+// every entry logged through logger.FromContext during the request carries the ID.
+func RequestIDMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validID(id) {
+				id = IDGenerator()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey, id)
+			ctx = logger.IntoContext(ctx, logger.FromContext(ctx).WithField(requestIDField, id))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CorrelationMiddleware propagates a correlation ID across service calls. It reuses
+// a valid incoming X-Correlation-ID header, then the request ID if RequestIDMiddleware
+// ran first, and otherwise generates one with IDGenerator. The ID is echoed in the
+// response header and stored in the request context (see CorrelationIDFromContext).
+func CorrelationMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(CorrelationIDHeader)
+			if !validID(id) {
+				id = RequestIDFromContext(r.Context())
+			}
+			if id == "" {
+				id = IDGenerator()
+			}
+			w.Header().Set(CorrelationIDHeader, id)
+			ctx := context.WithValue(r.Context(), correlationIDKey, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored by RequestIDMiddleware, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// CorrelationIDFromContext returns the correlation ID stored by CorrelationMiddleware, or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// validID reports whether a client-supplied ID is safe to reuse in headers and logs.
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Waryway/Wayframe/pkg/logger"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	if len(id) != 32 {
+		t.Errorf("expected a 32-character hex ID, got %q", id)
+	}
+	if seen != id {
+		t.Errorf("expected context ID %q to match header, got %q", id, seen)
+	}
+
+	// Incoming IDs are reused
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("expected incoming ID to be reused, got %q", got)
+	}
+}
+
+func TestIDGeneratorOverride(t *testing.T) {
+	original := IDGenerator
+	defer func() { IDGenerator = original }()
+	IDGenerator = func() string { return "custom-id" }
+
+	var requestID, correlationID string
+	handler := RequestIDMiddleware()(CorrelationMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestIDFromContext(r.Context())
+		correlationID = CorrelationIDFromContext(r.Context())
+	})))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if requestID != "custom-id" {
+		t.Errorf("expected RequestIDMiddleware to use IDGenerator, got %q", requestID)
+	}
+	if correlationID != "custom-id" {
+		t.Errorf("expected correlation ID to default to request ID, got %q", correlationID)
+	}
+
+	handler = CorrelationMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get(CorrelationIDHeader); got != "custom-id" {
+		t.Errorf("expected CorrelationMiddleware to use IDGenerator, got %q", got)
+	}
+}
+
+func TestRequestIDContextLogger(t *testing.T) {
+	orders := map[string][]func(log *logger.Logger) Middleware{
+		"request ID first": {
+			func(*logger.Logger) Middleware { return RequestIDMiddleware() },
+			LoggerMiddleware,
+		},
+		"logger first": {
+			LoggerMiddleware,
+			func(*logger.Logger) Middleware { return RequestIDMiddleware() },
+		},
+	}
+	for name, stack := range orders {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.NewWithHandler(slog.NewTextHandler(&buf, nil))
+
+			srv := New(Config{Addr: ":0"})
+			for _, mw := range stack {
+				srv.Use(mw(log))
+			}
+			srv.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+				logger.FromContext(r.Context()).Info("listing orders")
+			})
+
+			ts := httptest.NewServer(srv.Handler())
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + "/orders")
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			id := resp.Header.Get(RequestIDHeader)
+			if id == "" {
+				t.Fatal("expected X-Request-ID response header")
+			}
+			if !strings.Contains(buf.String(), "request_id="+id) {
+				t.Errorf("expected handler log entry with request_id=%s, got: %s", id, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Package responsewriter provides composable http.ResponseWriter wrappers for
+// middleware that observes or transforms responses.
+//
+// Each wrapper treats the writer it is given as just another ResponseWriter,
+// never assuming it is the connection itself, so middleware built on them can
+// be stacked in any order. For example, an ETag middleware using Buffer inside
+// a gzip middleware using Writer hashes the uncompressed body, and the gzip
+// middleware then compresses what the ETag middleware sends.
+//
+// Both wrappers implement Unwrap, so http.ResponseController can reach
+// deadline and hijacking support on the underlying writer, and both handle
+// flushing themselves so streamed responses pass through correctly.
+package responsewriter
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// Writer wraps an http.ResponseWriter, recording the status code and the
+// number of body bytes the handler writes. A middleware can intercept the
+// body by registering an OnWriteHeader hook that calls SetBodyWriter, for
+// example to compress it.
+type Writer struct {
+	rw          http.ResponseWriter
+	body        io.Writer
+	hooks       []func(status int)
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// New returns a Writer wrapping w.
+func New(w http.ResponseWriter) *Writer {
+	return &Writer{rw: w, body: w, status: http.StatusOK}
+}
+
+// OnWriteHeader registers fn to run once, just before the response status is
+// sent, when headers can still be changed. Hooks run in registration order.
+func (w *Writer) OnWriteHeader(fn func(status int)) {
+	w.hooks = append(w.hooks, fn)
+}
+
+// SetBodyWriter sends subsequent body writes to body instead of the wrapped
+// ResponseWriter. It is typically called from an OnWriteHeader hook, with body
+// writing through to the wrapped ResponseWriter. If body has a Flush() error
+// method, Flush calls it before flushing the wrapped ResponseWriter.
+func (w *Writer) SetBodyWriter(body io.Writer) {
+	w.body = body
+}
+
+// Header returns the wrapped ResponseWriter's header map.
+func (w *Writer) Header() http.Header {
+	return w.rw.Header()
+}
+
+// WriteHeader records the first final status code and runs the OnWriteHeader
+// hooks before delegating. Informational (1xx) statuses are passed through.
+func (w *Writer) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.rw.WriteHeader(status)
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	for _, fn := range w.hooks {
+		fn(status)
+	}
+	w.rw.WriteHeader(status)
+}
+
+// Write sends b to the body writer, sending a 200 status first if none was set.
+func (w *Writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.body.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Status returns the status code sent, or 200 if none has been sent yet.
+func (w *Writer) Status() int {
+	return w.status
+}
+
+// BytesWritten returns the number of body bytes written by the handler,
+// before any transformation by the body writer.
+func (w *Writer) BytesWritten() int64 {
+	return w.bytes
+}
+
+// FlushError flushes the body writer, if it supports flushing, and then the
+// wrapped ResponseWriter. It is used by http.ResponseController.
+func (w *Writer) FlushError() error {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.body.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.rw).Flush()
+}
+
+// Flush implements http.Flusher.
+func (w *Writer) Flush() {
+	w.FlushError()
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.rw
+}
+
+// Buffer holds a response's status and body in memory so a middleware can
+// inspect the complete body, for example to compute an ETag, before deciding
+// what to send. Headers are set directly on the wrapped ResponseWriter's
+// header map, since nothing is sent until Send.
+//
+// If the handler flushes, Buffer sends what it holds and streams the rest of
+// the response directly; Streaming then reports true and Send does nothing.
+type Buffer struct {
+	rw          http.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	streaming   bool
+}
+
+// NewBuffer returns a Buffer wrapping w.
+func NewBuffer(w http.ResponseWriter) *Buffer {
+	return &Buffer{rw: w, status: http.StatusOK}
+}
+
+// Header returns the wrapped ResponseWriter's header map.
+func (b *Buffer) Header() http.Header {
+	return b.rw.Header()
+}
+
+// WriteHeader records the first final status code. Informational (1xx)
+// statuses are passed through immediately.
+func (b *Buffer) WriteHeader(status int) {
+	if b.streaming {
+		b.rw.WriteHeader(status)
+		return
+	}
+	if b.wroteHeader {
+		return
+	}
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		b.rw.WriteHeader(status)
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+// Write appends p to the buffered body, or writes it directly when streaming.
+func (b *Buffer) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.rw.Write(p)
+	}
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// Status returns the buffered status code, or 200 if none was set.
+func (b *Buffer) Status() int {
+	return b.status
+}
+
+// Body returns the buffered body. It is valid until the next Write.
+func (b *Buffer) Body() []byte {
+	return b.body.Bytes()
+}
+
+// Streaming reports whether the handler flushed, so the response has already
+// been sent.
+func (b *Buffer) Streaming() bool {
+	return b.streaming
+}
+
+// Send writes the buffered status and body to the wrapped ResponseWriter.
+// It does nothing once the response is streaming.
+func (b *Buffer) Send() error {
+	if b.streaming {
+		return nil
+	}
+	b.streaming = true
+	b.rw.WriteHeader(b.status)
+	_, err := b.rw.Write(b.body.Bytes())
+	b.body.Reset()
+	return err
+}
+
+// FlushError sends the buffered response and switches to streaming, then
+// flushes the wrapped ResponseWriter. It is used by http.ResponseController.
+func (b *Buffer) FlushError() error {
+	if err := b.Send(); err != nil {
+		return err
+	}
+	return http.NewResponseController(b.rw).Flush()
+}
+
+// Flush implements http.Flusher.
+func (b *Buffer) Flush() {
+	b.FlushError()
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (b *Buffer) Unwrap() http.ResponseWriter {
+	return b.rw
+}
@@ -0,0 +1,98 @@
+package responsewriter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriterRecordsStatusAndBytes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := New(rec)
+
+	if w.Status() != http.StatusOK {
+		t.Errorf("expected default status 200, got %d", w.Status())
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte("hello"))
+
+	if w.Status() != http.StatusCreated {
+		t.Errorf("expected first status 201 to be recorded, got %d", w.Status())
+	}
+	if w.BytesWritten() != 5 {
+		t.Errorf("expected 5 bytes written, got %d", w.BytesWritten())
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != "hello" {
+		t.Errorf("expected response to pass through, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWriterBodyInterception(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := New(rec)
+
+	var intercepted bytes.Buffer
+	var hookStatus int
+	w.OnWriteHeader(func(status int) {
+		hookStatus = status
+		w.Header().Set("X-Intercepted", "true")
+		w.SetBodyWriter(&intercepted)
+	})
+	w.Write([]byte("secret"))
+
+	if hookStatus != http.StatusOK {
+		t.Errorf("expected hook to see implicit status 200, got %d", hookStatus)
+	}
+	if rec.Header().Get("X-Intercepted") != "true" {
+		t.Error("expected hook to be able to set headers before they are sent")
+	}
+	if intercepted.String() != "secret" || rec.Body.Len() != 0 {
+		t.Errorf("expected body to go to the body writer, got %q and %q", intercepted.String(), rec.Body.String())
+	}
+	if w.BytesWritten() != 6 {
+		t.Errorf("expected 6 bytes written, got %d", w.BytesWritten())
+	}
+}
+
+func TestBufferHoldsResponseUntilSend(t *testing.T) {
+	rec := httptest.NewRecorder()
+	b := NewBuffer(rec)
+
+	b.WriteHeader(http.StatusAccepted)
+	b.Write([]byte("queued"))
+	if rec.Body.Len() != 0 || rec.Code != http.StatusOK {
+		t.Fatal("expected nothing to be sent before Send")
+	}
+	if b.Status() != http.StatusAccepted || string(b.Body()) != "queued" {
+		t.Errorf("expected buffered 202 %q, got %d %q", "queued", b.Status(), b.Body())
+	}
+
+	if err := b.Send(); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "queued" {
+		t.Errorf("expected 202 %q after Send, got %d %q", "queued", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBufferStreamsAfterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	b := NewBuffer(rec)
+
+	b.Write([]byte("event: 1\n"))
+	if err := http.NewResponseController(b).Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if !b.Streaming() || !rec.Flushed {
+		t.Fatal("expected Flush to send the buffered response and switch to streaming")
+	}
+	b.Write([]byte("event: 2\n"))
+	b.Send()
+
+	if got := rec.Body.String(); got != "event: 1\nevent: 2\n" || strings.Count(got, "event: 1") != 1 {
+		t.Errorf("expected each event written once, got %q", got)
+	}
+}
@@ -4,10 +4,19 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -17,6 +26,37 @@ type Server struct {
 	httpServer *http.Server
 	mux        *http.ServeMux
 	middleware []Middleware
+	pre        []Middleware
+	notFound   http.Handler
+	notAllowed http.Handler
+	onShutdown []func(ctx context.Context)
+	onExit     []func()
+	observers  []ShutdownObserver
+	routes     []route
+	routesMu   sync.Mutex
+
+	metrics     *Metrics
+	metricsOnce sync.Once
+
+	disableSignals bool
+	dynamicRoutes  bool
+	started        atomic.Bool
+	stopping       chan struct{} // closed when shutdown begins
+	stopOnce       sync.Once
+	listenRetries  int
+	retryDelay     time.Duration
+	activeConns    atomic.Int64
+	boundAddr      atomic.Value // string, set once the listener is bound
+
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// route is a registered pattern and its middleware-wrapped handler.
+type route struct {
+	pattern string
+	handler http.Handler
 }
 
 // Middleware is a function that wraps an http.Handler.
@@ -28,23 +68,199 @@ type Config struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// ErrorLog receives low-level errors from the underlying http.Server.
+	// If nil, the standard log package's logger is used. To route errors
+	// into a Wayframe logger, use log.New(l.Writer(logger.ErrorLevel), "", 0).
+	ErrorLog *log.Logger
+
+	// CertFile and KeyFile enable TLS when both are set. The certificate is
+	// loaded when the server starts and can be reloaded without a restart
+	// using ReloadCertificates.
+	CertFile string
+	KeyFile  string
+
+	// DisableSignalHandling stops Start from listening for SIGINT and SIGTERM.
+	// Use it when embedding the server in an app that already owns signal
+	// handling; the server then stops only via StartContext's context or an
+	// explicit Shutdown.
+	DisableSignalHandling bool
+
+	// ListenRetries is how many more times Start tries to bind the listener
+	// when the address is still in use, for example while a restarted
+	// container's previous port lingers. Other listen errors fail immediately.
+	// ListenRetryDelay is the wait between attempts, one second if unset.
+	// Retrying stops as soon as shutdown begins.
+	ListenRetries    int
+	ListenRetryDelay time.Duration
+
+	// MaxHeaderBytes caps the size of request headers, including the request
+	// line, as http.Server.MaxHeaderBytes does; 0 uses net/http's default.
+	// MaxQueryStringBytes and MaxHeaderCount add finer limits on the raw query
+	// string and the number of header fields, answered with 431 Request Header
+	// Fields Too Large before any middleware runs (see RequestLimitsMiddleware).
+	// 0 means no limit.
+	MaxHeaderBytes      int
+	MaxQueryStringBytes int
+	MaxHeaderCount      int
+
+	// DynamicRoutes allows Handle and HandleFunc after the server has
+	// started, for apps that add routes at runtime, such as plugins. Without
+	// it, registering a route after Start panics, since such late routes are
+	// usually a bug. Replace is rejected after Start either way, because it
+	// rebuilds the mux.
+	DynamicRoutes bool
 }
 
 // New creates a new Server with the given configuration.
 func New(cfg Config) *Server {
 	mux := http.NewServeMux()
 	
-	return &Server{
+	s := &Server{
 		httpServer: &http.Server{
-			Addr:         cfg.Addr,
-			Handler:      mux,
-			ReadTimeout:  cfg.ReadTimeout,
-			WriteTimeout: cfg.WriteTimeout,
-			IdleTimeout:  cfg.IdleTimeout,
+			Addr:           cfg.Addr,
+			Handler:        mux,
+			ReadTimeout:    cfg.ReadTimeout,
+			WriteTimeout:   cfg.WriteTimeout,
+			IdleTimeout:    cfg.IdleTimeout,
+			ErrorLog:       cfg.ErrorLog,
+			MaxHeaderBytes: cfg.MaxHeaderBytes,
 		},
-		mux:        mux,
-		middleware: make([]Middleware, 0),
+		mux:            mux,
+		middleware:     make([]Middleware, 0),
+		disableSignals: cfg.DisableSignalHandling,
+		dynamicRoutes:  cfg.DynamicRoutes,
+		stopping:       make(chan struct{}),
+		listenRetries:  cfg.ListenRetries,
+		retryDelay:     cfg.ListenRetryDelay,
+	}
+	s.httpServer.ConnState = s.trackConn
+	if cfg.MaxQueryStringBytes > 0 || cfg.MaxHeaderCount > 0 {
+		s.pre = append(s.pre, RequestLimitsMiddleware(cfg.MaxQueryStringBytes, cfg.MaxHeaderCount))
+	}
+	s.rebuildHandler()
+	
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		s.certFile = cfg.CertFile
+		s.keyFile = cfg.KeyFile
+		s.httpServer.TLSConfig = &tls.Config{
+			GetCertificate: s.getCertificate,
+		}
+	}
+	return s
+}
+
+// ReloadCertificates re-reads CertFile and KeyFile and atomically swaps in the
+// new certificate. New TLS handshakes use the fresh certificate, while existing
+// connections are unaffected. On error the previous certificate stays in use.
+func (s *Server) ReloadCertificates() error {
+	if s.certFile == "" {
+		return errors.New("server: TLS is not configured")
+	}
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	s.cert.Store(&cert)
+	return nil
+}
+
+// getCertificate returns the current certificate for a TLS handshake.
+func (s *Server) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := s.cert.Load()
+	if cert == nil {
+		return nil, errors.New("server: no TLS certificate loaded")
+	}
+	return cert, nil
+}
+
+// listenAndServe binds the listener and starts serving, using TLS if a
+// certificate is configured. If ready is non-nil, it is closed once the
+// listener is bound and before serving begins.
+func (s *Server) listenAndServe(ready chan<- struct{}) error {
+	s.started.Store(true)
+	if s.certFile != "" {
+		if err := s.ReloadCertificates(); err != nil {
+			return err
+		}
+	}
+
+	addr := s.httpServer.Addr
+	if addr == "" {
+		addr = ":http"
+		if s.certFile != "" {
+			addr = ":https"
+		}
+	}
+	ln, err := s.listen(addr)
+	if err != nil {
+		return err
+	}
+	s.boundAddr.Store(ln.Addr().String())
+	if ready != nil {
+		close(ready)
+	}
+
+	if s.certFile == "" {
+		return s.httpServer.Serve(ln)
+	}
+	// Certificates come from TLSConfig.GetCertificate
+	return s.httpServer.ServeTLS(ln, "", "")
+}
+
+// defaultListenRetryDelay is the wait between listen attempts when
+// Config.ListenRetryDelay is unset.
+const defaultListenRetryDelay = time.Second
+
+// listen binds a TCP listener on addr, retrying up to ListenRetries times
+// while the address is in use. It stops retrying with http.ErrServerClosed
+// once shutdown begins.
+func (s *Server) listen(addr string) (net.Listener, error) {
+	delay := s.retryDelay
+	if delay <= 0 {
+		delay = defaultListenRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		ln, err := net.Listen("tcp", addr)
+		if err == nil || attempt >= s.listenRetries || !errors.Is(err, syscall.EADDRINUSE) {
+			return ln, err
+		}
+		fmt.Printf("Address %s in use, retrying in %v (attempt %d of %d)...\n", addr, delay, attempt+1, s.listenRetries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-s.stopping:
+			timer.Stop()
+			return nil, http.ErrServerClosed
+		case <-timer.C:
+		}
+	}
+}
+
+// Addr returns the address the server is listening on. Once the server has
+// started, this is the bound address, which reveals the actual port when
+// Config.Addr uses port 0. Before that it is the configured address.
+func (s *Server) Addr() string {
+	if addr, ok := s.boundAddr.Load().(string); ok {
+		return addr
 	}
+	return s.httpServer.Addr
+}
+
+// trackConn maintains the count of open connections.
+func (s *Server) trackConn(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		s.activeConns.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		s.activeConns.Add(-1)
+	}
+}
+
+// ActiveConns returns the number of currently open client connections,
+// including idle keep-alive connections.
+func (s *Server) ActiveConns() int {
+	return int(s.activeConns.Load())
 }
 
 // Use adds middleware to the server. Middleware is applied in the order it's added.
@@ -52,14 +268,183 @@ func (s *Server) Use(mw Middleware) {
 	s.middleware = append(s.middleware, mw)
 }
 
+// Pre adds middleware that runs before routing. Unlike Use, which wraps each
+// registered handler, Pre middleware wraps the whole mux, so it also sees
+// requests that match no pattern and can rewrite the request before it is routed.
+// Pre middleware is applied in the order it's added, outermost first.
+func (s *Server) Pre(mw Middleware) {
+	s.pre = append(s.pre, mw)
+	s.rebuildHandler()
+}
+
+// Handler returns the server's root http.Handler, including Pre middleware.
+// It is useful for serving the application with httptest or a custom listener.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// NotFound sets the handler for requests that match no registered pattern.
+// Middleware added with Use before this call is applied to it, so 404s can be
+// logged and rendered consistently (for example as JSON errors).
+func (s *Server) NotFound(handler http.Handler) {
+	s.notFound = s.wrap(handler)
+}
+
+// MethodNotAllowed sets the handler for requests whose path matches a registered
+// pattern but whose method does not. The Allow header is set before the handler
+// runs. Middleware added with Use before this call is applied to it.
+func (s *Server) MethodNotAllowed(handler http.Handler) {
+	s.notAllowed = s.wrap(handler)
+}
+
+// dispatch routes a request through the mux, sending unmatched requests to
+// the custom NotFound and MethodNotAllowed handlers when they are set.
+func (s *Server) dispatch(w http.ResponseWriter, r *http.Request) {
+	if s.notFound == nil && s.notAllowed == nil {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	h, pattern := s.mux.Handler(r)
+	if pattern != "" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// ServeMux returns an internal error handler for unmatched requests;
+	// probe it to tell a 405 (which sets Allow) from a 404.
+	probe := &probeWriter{header: make(http.Header)}
+	h.ServeHTTP(probe, r)
+
+	fallback := s.notFound
+	if probe.status == http.StatusMethodNotAllowed {
+		fallback = s.notAllowed
+		if allow := probe.header.Get("Allow"); allow != "" && fallback != nil {
+			w.Header().Set("Allow", allow)
+		}
+	}
+	if fallback == nil {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+	fallback.ServeHTTP(w, r)
+}
+
+// probeWriter is a ResponseWriter that records the header and status and discards the body.
+type probeWriter struct {
+	header http.Header
+	status int
+}
+
+func (p *probeWriter) Header() http.Header         { return p.header }
+func (p *probeWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (p *probeWriter) WriteHeader(status int)      { p.status = status }
+
+// rebuildHandler wraps the current mux with Pre middleware.
+func (s *Server) rebuildHandler() {
+	var handler http.Handler = http.HandlerFunc(s.dispatch)
+	for i := len(s.pre) - 1; i >= 0; i-- {
+		handler = s.pre[i](handler)
+	}
+	if len(s.pre) > 0 {
+		// Pre middleware runs before routing; expose the server so it can
+		// consult the registered routes (see StripTrailingSlashMiddleware).
+		inner := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), serverKey{}, s)))
+		})
+	}
+	s.httpServer.Handler = handler
+}
+
+// serverKey is the context key under which Pre middleware finds its Server.
+type serverKey struct{}
+
+// patternFor returns the pattern that would serve r if its path were path, or ""
+// if no registered pattern matches. For paths that ServeMux redirects, such as
+// /static for a /static/ subtree, it is the pattern matched after the redirect.
+func (s *Server) patternFor(r *http.Request, path string) string {
+	r2 := new(http.Request)
+	*r2 = *r
+	u := *r.URL
+	u.Path = path
+	u.RawPath = ""
+	r2.URL = &u
+	_, pattern := s.mux.Handler(r2)
+	return pattern
+}
+
 // Handle registers a handler for the given pattern.
 // Middleware is applied to the handler.
+// Registering the same pattern twice panics with a message naming the pattern;
+// use Replace to intentionally override an existing route. Routes must be
+// registered before the server starts unless Config.DynamicRoutes is set;
+// Handle panics otherwise.
 func (s *Server) Handle(pattern string, handler http.Handler) {
+	if s.started.Load() && !s.dynamicRoutes {
+		panic(fmt.Sprintf("server: cannot register pattern %q after Start; register routes before starting or set Config.DynamicRoutes", pattern))
+	}
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+	for _, r := range s.routes {
+		if r.pattern == pattern {
+			panic(fmt.Sprintf("server: duplicate registration for pattern %q; use Replace to override it", pattern))
+		}
+	}
+
+	handler = s.wrap(handler)
+	s.register(s.mux, pattern, handler)
+	s.routes = append(s.routes, route{pattern: pattern, handler: handler})
+}
+
+// Replace registers handler for pattern, replacing any existing registration.
+// Because http.ServeMux cannot unregister patterns, the mux is rebuilt, so
+// Replace must be called before the server starts; it panics afterwards.
+func (s *Server) Replace(pattern string, handler http.Handler) {
+	if s.started.Load() {
+		panic(fmt.Sprintf("server: cannot replace pattern %q after Start; the mux can only be rebuilt before starting", pattern))
+	}
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+	handler = s.wrap(handler)
+
+	replaced := false
+	for i := range s.routes {
+		if s.routes[i].pattern == pattern {
+			s.routes[i].handler = handler
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.routes = append(s.routes, route{pattern: pattern, handler: handler})
+	}
+
+	mux := http.NewServeMux()
+	for _, r := range s.routes {
+		s.register(mux, r.pattern, r.handler)
+	}
+	s.mux = mux
+	s.rebuildHandler()
+}
+
+// wrap applies the server's middleware to handler.
+func (s *Server) wrap(handler http.Handler) http.Handler {
 	// Apply middleware in reverse order so first added is outermost
 	for i := len(s.middleware) - 1; i >= 0; i-- {
 		handler = s.middleware[i](handler)
 	}
-	s.mux.Handle(pattern, handler)
+	return handler
+}
+
+// register adds handler to mux, turning ServeMux's conflict panics into
+// a message that names the offending pattern.
+func (s *Server) register(mux *http.ServeMux, pattern string, handler http.Handler) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(fmt.Sprintf("server: cannot register pattern %q: %v", pattern, rec))
+		}
+	}()
+	mux.Handle(pattern, handler)
 }
 
 // HandleFunc registers a handler function for the given pattern.
@@ -68,72 +453,384 @@ func (s *Server) HandleFunc(pattern string, handlerFunc http.HandlerFunc) {
 	s.Handle(pattern, handlerFunc)
 }
 
+// HandleWithTimeout registers handler for pattern like Handle, limiting it to
+// timeout with TimeoutMiddleware. Use it to give routes such as report exports
+// a longer or shorter limit than the rest of the server. If a global
+// TimeoutMiddleware is also in use, the tighter timeout wins.
+func (s *Server) HandleWithTimeout(pattern string, handler http.Handler, timeout time.Duration) {
+	s.Handle(pattern, TimeoutMiddleware(timeout)(handler))
+}
+
 // Start starts the HTTP server and blocks until a shutdown signal is received.
-// It performs graceful shutdown with a timeout.
+// It performs graceful shutdown with a timeout. If Shutdown is called
+// directly, Start returns nil once the server stops accepting connections.
+// With Config.DisableSignalHandling, signals are ignored.
 func (s *Server) Start(shutdownTimeout time.Duration) error {
+	return s.StartWithReady(shutdownTimeout, nil)
+}
+
+// StartWithReady is like Start, but closes ready once the listener is bound and
+// the server is about to accept connections. Tests and orchestration can wait
+// on it instead of sleeping. If binding fails, ready is never closed and the
+// error is returned:
+//
+//	ready := make(chan struct{})
+//	go func() { errCh <- srv.StartWithReady(30*time.Second, ready) }()
+//	select {
+//	case <-ready:
+//	case err := <-errCh:
+//	    return err
+//	}
+func (s *Server) StartWithReady(shutdownTimeout time.Duration, ready chan<- struct{}) error {
+	ctx, cancel := s.signalContext()
+	defer cancel()
+	return s.run(ctx, shutdownTimeout, ready)
+}
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM,
+// unless signal handling is disabled.
+func (s *Server) signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.disableSignals {
+		return ctx, cancel
+	}
+
 	// Channel to listen for interrupt signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	
-	// Channel to receive server errors
+	go func() {
+		select {
+		case sig := <-quit:
+			fmt.Printf("Received signal: %v, shutting down gracefully...\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(quit)
+		cancel()
+	}
+}
+
+// StartContext starts the HTTP server and blocks until ctx is done, then
+// performs graceful shutdown with a timeout. It never listens for signals.
+// If Shutdown is called directly, StartContext returns nil once the server
+// stops accepting connections.
+func (s *Server) StartContext(ctx context.Context, shutdownTimeout time.Duration) error {
+	return s.run(ctx, shutdownTimeout, nil)
+}
+
+// run serves until ctx is done, then shuts down gracefully.
+// OnExit hooks run once serving has fully stopped, whatever the outcome.
+func (s *Server) run(ctx context.Context, shutdownTimeout time.Duration, ready chan<- struct{}) error {
+	defer s.runExitHooks()
+
+	// Channel to receive the result of serving
 	errChan := make(chan error, 1)
-	
+
 	// Start server in a goroutine
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
+		errChan <- s.listenAndServe(ready)
 	}()
-	
-	// Wait for interrupt signal or error
+
+	// Wait for cancellation or for the server to stop
 	select {
 	case err := <-errChan:
+		if err == http.ErrServerClosed {
+			return nil
+		}
 		return err
-	case sig := <-quit:
-		fmt.Printf("Received signal: %v, shutting down gracefully...\n", sig)
+	case <-ctx.Done():
 	}
-	
+	start := time.Now()
+	s.notifyShutdown(ShutdownSignaled, start)
+
 	// Create a context with timeout for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	
+
+	// Report connections that are still draining until shutdown completes
+	drained := make(chan struct{})
+	go s.reportDrain(drained)
+
 	// Attempt graceful shutdown
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	err := s.shutdown(shutdownCtx, start)
+	close(drained)
+
+	// Serve returns as soon as Shutdown is called, so this does not outlast the timeout
+	<-errChan
+	if err != nil {
+		return fmt.Errorf("server forced to shutdown with %d active connections: %w", s.ActiveConns(), err)
 	}
-	
+
 	fmt.Println("Server exited gracefully")
 	return nil
 }
 
+// drainLogInterval is how often the number of draining connections is reported.
+const drainLogInterval = time.Second
+
+// reportDrain periodically prints the number of open connections until done is closed.
+func (s *Server) reportDrain(done <-chan struct{}) {
+	fmt.Printf("Draining %d active connections...\n", s.ActiveConns())
+	
+	ticker := time.NewTicker(drainLogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if n := s.ActiveConns(); n > 0 {
+				fmt.Printf("Waiting for %d active connections to drain...\n", n)
+			}
+		}
+	}
+}
+
 // Shutdown gracefully shuts down the server with the given context.
+// After in-flight requests have drained, OnShutdown hooks run in registration
+// order with the same context, so they share its deadline.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	return s.shutdown(ctx, time.Now())
+}
+
+// shutdown drains the server and runs OnShutdown hooks, reporting each phase
+// to observers with the time elapsed since start.
+func (s *Server) shutdown(ctx context.Context, start time.Time) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+	s.notifyShutdown(ShutdownDraining, start)
+	err := s.httpServer.Shutdown(ctx)
+	s.notifyShutdown(ShutdownHooks, start)
+	for _, fn := range s.onShutdown {
+		fn(ctx)
+	}
+	s.notifyShutdown(ShutdownComplete, start)
+	return err
+}
+
+// ShutdownPhase is a stage of graceful shutdown reported to a ShutdownObserver.
+type ShutdownPhase int
+
+const (
+	// ShutdownSignaled means a shutdown signal was received or the context
+	// passed to StartContext was cancelled. It is not reported when Shutdown
+	// is called directly.
+	ShutdownSignaled ShutdownPhase = iota
+	// ShutdownDraining means the server stopped accepting connections and is
+	// waiting for in-flight requests.
+	ShutdownDraining
+	// ShutdownHooks means draining has ended and OnShutdown hooks are running.
+	ShutdownHooks
+	// ShutdownComplete means the hooks have returned. Shutdown may still have
+	// timed out before every connection drained.
+	ShutdownComplete
+)
+
+// String returns the lower-case name of the phase.
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownSignaled:
+		return "signaled"
+	case ShutdownDraining:
+		return "draining"
+	case ShutdownHooks:
+		return "hooks"
+	case ShutdownComplete:
+		return "complete"
+	default:
+		return "unknown"
+	}
+}
+
+// ShutdownObserver is called as graceful shutdown enters each phase, with the
+// time elapsed since shutdown began.
+type ShutdownObserver func(phase ShutdownPhase, elapsed time.Duration)
+
+// ObserveShutdown registers fn to be told about shutdown progress, so
+// orchestration dashboards can show where a slow shutdown is spending its time:
+//
+//	srv.ObserveShutdown(func(phase server.ShutdownPhase, elapsed time.Duration) {
+//	    log.Infof("shutdown %s after %v", phase, elapsed)
+//	})
+//
+// Observers run synchronously in registration order, so they should return quickly.
+func (s *Server) ObserveShutdown(fn ShutdownObserver) {
+	s.observers = append(s.observers, fn)
+}
+
+// notifyShutdown reports phase to the observers.
+func (s *Server) notifyShutdown(phase ShutdownPhase, start time.Time) {
+	elapsed := time.Since(start)
+	for _, fn := range s.observers {
+		fn(phase, elapsed)
+	}
+}
+
+// OnShutdown registers a hook that runs during graceful shutdown.
+// The hook receives the shutdown context; use RemainingBudget to find out
+// how much of the shutdown timeout is left.
+func (s *Server) OnShutdown(fn func(ctx context.Context)) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// OnExit registers a hook that runs after the server has fully stopped, when
+// Start, StartWithReady, or StartContext is about to return. Unlike OnShutdown
+// hooks, exit hooks run outside the shutdown timeout and also run when
+// shutdown times out or serving fails, so use them for cleanup that must
+// always happen, such as closing log files or removing a pid file.
+// Hooks run in registration order.
+func (s *Server) OnExit(fn func()) {
+	s.onExit = append(s.onExit, fn)
+}
+
+// runExitHooks calls the OnExit hooks in registration order.
+func (s *Server) runExitHooks() {
+	for _, fn := range s.onExit {
+		fn()
+	}
+}
+
+// RemainingBudget returns the time left before ctx's deadline.
+// It returns zero once the deadline has passed, and the maximum duration
+// if ctx has no deadline.
+func RemainingBudget(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Duration(math.MaxInt64)
+	}
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+	return 0
 }
 
-// LoggingMiddleware logs each HTTP request with method, path, and duration.
+// LoggingMiddleware logs each HTTP request with method, route template, and duration.
 func LoggingMiddleware(logger interface{ Infof(string, ...interface{}) }) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			next.ServeHTTP(w, r)
 			duration := time.Since(start)
-			logger.Infof("%s %s - %v", r.Method, r.URL.Path, duration)
+			logger.Infof("%s %s - %v", r.Method, RouteTemplate(r), duration)
 		})
 	}
 }
 
-// RecoveryMiddleware recovers from panics and returns a 500 Internal Server Error.
-func RecoveryMiddleware(logger interface{ Errorf(string, ...interface{}) }) Middleware {
+// RouteTemplate returns the registered pattern that matched r, without its
+// method or host, such as "/users/{id}" for a request to /users/42. Using it
+// instead of the raw path keeps log and metric labels low-cardinality and
+// avoids leaking IDs. It falls back to r.URL.Path when the request has not
+// been matched to a route, for example in Pre middleware or for 404s.
+func RouteTemplate(r *http.Request) string {
+	pattern := r.Pattern
+	if pattern == "" {
+		return r.URL.Path
+	}
+	// Patterns have the form [METHOD ][HOST]/[PATH]
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimSpace(rest)
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// RecoveryOptions configures the response written by RecoveryMiddleware.
+type RecoveryOptions struct {
+	// ResponseBody is written for every recovered panic, with the status
+	// chosen as described on RecoveryMiddleware. If nil, the status text is used.
+	ResponseBody []byte
+	// ContentType is the Content-Type of ResponseBody.
+	// Defaults to "text/plain; charset=utf-8".
+	ContentType string
+	// Reporter, if set, is called with each recovered value, the request, and
+	// the goroutine's stack trace, for forwarding to an error-tracking service.
+	// It runs before the response is written.
+	Reporter func(recovered interface{}, r *http.Request, stack []byte)
+}
+
+// RecoveryMiddleware recovers from panics and returns an error response.
+// The recovered value determines the response:
+//   - http.ErrAbortHandler is re-panicked so net/http can abort the response
+//   - an error with a StatusCode() int method responds with that status;
+//     an *Error is rendered as JSON with WriteError
+//   - anything else responds with 500 Internal Server Error
+//
+// By default the body is the plain-text status text. Pass RecoveryOptions to
+// send a custom body instead, such as a JSON error for API servers.
+func RecoveryMiddleware(logger interface{ Errorf(string, ...interface{}) }, opts ...RecoveryOptions) Middleware {
+	var o RecoveryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.ContentType == "" {
+		o.ContentType = "text/plain; charset=utf-8"
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
-				if err := recover(); err != nil {
-					logger.Errorf("panic recovered: %v", err)
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					status := panicStatus(rec)
+					if status >= 500 {
+						logger.Errorf("panic recovered: %v", rec)
+					} else {
+						logger.Errorf("panic recovered with status %d: %v", status, rec)
+					}
+					if o.Reporter != nil {
+						o.Reporter(rec, r, debug.Stack())
+					}
+					if o.ResponseBody == nil {
+						if err, ok := rec.(error); ok && errors.As(err, new(*Error)) {
+							WriteError(w, err)
+							return
+						}
+						http.Error(w, http.StatusText(status), status)
+						return
+					}
+					w.Header().Set("Content-Type", o.ContentType)
+					w.Header().Set("X-Content-Type-Options", "nosniff")
+					w.WriteHeader(status)
+					w.Write(o.ResponseBody)
 				}
 			}()
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// RecoveryMiddlewareWithReporter is RecoveryMiddleware with reporter set as
+// RecoveryOptions.Reporter, so panics are also shipped to an external sink:
+//
+//	srv.Use(server.RecoveryMiddlewareWithReporter(log, func(rec interface{}, r *http.Request, stack []byte) {
+//	    tracker.CapturePanic(rec, r.URL.Path, stack)
+//	}))
+//
+// A nil reporter behaves exactly like RecoveryMiddleware.
+func RecoveryMiddlewareWithReporter(logger interface{ Errorf(string, ...interface{}) }, reporter func(recovered interface{}, r *http.Request, stack []byte)) Middleware {
+	return RecoveryMiddleware(logger, RecoveryOptions{Reporter: reporter})
+}
+
+// statusCoder is implemented by errors that map to an HTTP status code.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// panicStatus maps a recovered panic value to an HTTP status code.
+func panicStatus(rec interface{}) int {
+	if err, ok := rec.(error); ok {
+		var sc statusCoder
+		if errors.As(err, &sc) {
+			if code := sc.StatusCode(); code >= 400 && code <= 599 {
+				return code
+			}
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -2,11 +2,18 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/signal"
+	"reflect"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -176,3 +183,710 @@ func TestMiddlewareOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestRemainingBudgetAcrossShutdownHooks(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+
+	var budgets []time.Duration
+	srv.OnShutdown(func(ctx context.Context) {
+		budgets = append(budgets, RemainingBudget(ctx))
+		time.Sleep(20 * time.Millisecond)
+	})
+	srv.OnShutdown(func(ctx context.Context) {
+		budgets = append(budgets, RemainingBudget(ctx))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown should not error: %v", err)
+	}
+
+	if len(budgets) != 2 {
+		t.Fatalf("expected both hooks to run, got %d", len(budgets))
+	}
+	if budgets[0] <= 0 || budgets[0] > 5*time.Second {
+		t.Errorf("expected first budget within the timeout, got %v", budgets[0])
+	}
+	if budgets[1] >= budgets[0] {
+		t.Errorf("expected budget to decrease, got %v then %v", budgets[0], budgets[1])
+	}
+}
+
+func TestRemainingBudgetWithoutDeadline(t *testing.T) {
+	if RemainingBudget(context.Background()) <= 0 {
+		t.Error("expected positive budget for context without deadline")
+	}
+}
+
+func TestConfigErrorLog(t *testing.T) {
+	errorLog := log.New(io.Discard, "", 0)
+	srv := New(Config{Addr: ":0", ErrorLog: errorLog})
+
+	if srv.httpServer.ErrorLog != errorLog {
+		t.Error("expected ErrorLog to be passed to the http.Server")
+	}
+}
+
+type appError struct {
+	code int
+}
+
+func (e appError) Error() string   { return fmt.Sprintf("app error %d", e.code) }
+func (e appError) StatusCode() int { return e.code }
+
+func TestRecoveryMiddlewarePanicClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected int
+	}{
+		{"string", "boom", http.StatusInternalServerError},
+		{"plain error", fmt.Errorf("boom"), http.StatusInternalServerError},
+		{"status error", appError{code: http.StatusConflict}, http.StatusConflict},
+		{"wrapped status error", fmt.Errorf("wrapped: %w", appError{code: http.StatusNotFound}), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLog := &mockLogger{}
+			handler := RecoveryMiddleware(mockLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic(tt.value)
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+			if len(mockLog.messages) != 1 {
+				t.Errorf("expected 1 error log, got %d", len(mockLog.messages))
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddlewareRepanicsAbortHandler(t *testing.T) {
+	mockLog := &mockLogger{}
+	handler := RecoveryMiddleware(mockLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", rec)
+		}
+		if len(mockLog.messages) != 0 {
+			t.Errorf("expected no error log for aborted handler, got %d", len(mockLog.messages))
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+		msg := fmt.Sprint(rec)
+		if !strings.Contains(msg, `"/users"`) || !strings.Contains(msg, "duplicate") {
+			t.Errorf("expected message naming the duplicate pattern, got: %s", msg)
+		}
+	}()
+	srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestReplace(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "original")
+	})
+	srv.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "other")
+	})
+
+	srv.Replace("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "replaced")
+	}))
+
+	for path, want := range map[string]string{"/users": "replaced", "/other": "other"} {
+		w := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Errorf("GET %s: expected %q, got %q", path, want, w.Body.String())
+		}
+	}
+}
+
+func TestRegistrationAfterStart(t *testing.T) {
+	start := func(srv *Server) (stop func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ready := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.run(ctx, 5*time.Second, ready)
+		}()
+		<-ready
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	stop := start(srv)
+	defer stop()
+
+	func() {
+		defer func() {
+			msg := fmt.Sprint(recover())
+			if !strings.Contains(msg, `"/late"`) || !strings.Contains(msg, "after Start") {
+				t.Errorf("expected a panic naming the late pattern, got: %s", msg)
+			}
+		}()
+		srv.HandleFunc("/late", func(w http.ResponseWriter, r *http.Request) {})
+	}()
+
+	dynamic := New(Config{Addr: "127.0.0.1:0", DynamicRoutes: true})
+	stopDynamic := start(dynamic)
+	defer stopDynamic()
+
+	dynamic.HandleFunc("/plugin", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "plugin")
+	})
+	resp, err := http.Get("http://" + dynamic.Addr() + "/plugin")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "plugin" {
+		t.Errorf("expected a route added at runtime to be served, got %q", body)
+	}
+}
+
+func TestActiveConns(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go srv.httpServer.Serve(ln)
+	defer srv.Shutdown(context.Background())
+
+	waitFor := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for srv.ActiveConns() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d active connections, got %d", want, srv.ActiveConns())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	waitFor(1)
+
+	conn.Close()
+	waitFor(0)
+}
+
+func TestNotFoundHandler(t *testing.T) {
+	mockLog := &mockLogger{}
+	srv := New(Config{Addr: ":0"})
+	srv.Use(LoggingMiddleware(mockLog))
+	srv.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {})
+	srv.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"not found"}`)
+	}))
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+	if w.Body.String() != `{"error":"not found"}` {
+		t.Errorf("expected custom body, got %q", w.Body.String())
+	}
+	if len(mockLog.messages) != 1 || !strings.Contains(mockLog.messages[0], "/missing") {
+		t.Errorf("expected logging middleware to run for 404, got %v", mockLog.messages)
+	}
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {})
+	srv.NotFound(http.NotFoundHandler())
+	srv.MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprint(w, `{"error":"method not allowed"}`)
+	}))
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("DELETE", "/users", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+	if w.Body.String() != `{"error":"method not allowed"}` {
+		t.Errorf("expected custom body, got %q", w.Body.String())
+	}
+	if allow := w.Header().Get("Allow"); !strings.Contains(allow, "GET") {
+		t.Errorf("expected Allow header to list GET, got %q", allow)
+	}
+
+	// Registered routes are unaffected
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 for registered route, got %d", w.Code)
+	}
+}
+
+func TestRecoveryMiddlewareOptions(t *testing.T) {
+	mockLog := &mockLogger{}
+	handler := RecoveryMiddleware(mockLog, RecoveryOptions{
+		ResponseBody: []byte(`{"error":"internal"}`),
+		ContentType:  "application/json",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", ct)
+	}
+	if body := w.Body.String(); body != `{"error":"internal"}` {
+		t.Errorf("expected JSON body, got %q", body)
+	}
+}
+
+func TestRecoveryMiddlewareWithReporter(t *testing.T) {
+	var (
+		recovered interface{}
+		path      string
+		stack     []byte
+	)
+	mockLog := &mockLogger{}
+	handler := RecoveryMiddlewareWithReporter(mockLog, func(rec interface{}, r *http.Request, st []byte) {
+		recovered, path, stack = rec, r.URL.Path, st
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/checkout", nil))
+
+	if recovered != "test panic" {
+		t.Errorf("expected reporter to receive the recovered value, got %v", recovered)
+	}
+	if path != "/checkout" {
+		t.Errorf("expected reporter to receive the request, got path %q", path)
+	}
+	if len(stack) == 0 {
+		t.Error("expected reporter to receive a stack trace")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+	if len(mockLog.messages) != 1 {
+		t.Errorf("expected panic to still be logged, got %d messages", len(mockLog.messages))
+	}
+}
+
+func TestRecoveryMiddlewareWithNilReporter(t *testing.T) {
+	handler := RecoveryMiddlewareWithReporter(&mockLogger{}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestDisableSignalHandling(t *testing.T) {
+	// Catch SIGINT in the test so the process survives if the server ignores it
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	srv := New(Config{Addr: "127.0.0.1:0", DisableSignalHandling: true})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(5 * time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find own process: %v", err)
+	}
+	if err := proc.Signal(os.Interrupt); err != nil {
+		t.Skipf("sending signals is not supported on this platform: %v", err)
+	}
+
+	select {
+	case <-sigs:
+	case <-time.After(time.Second):
+		t.Fatal("simulated signal was not delivered")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected server to ignore the signal, but Start returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected Start to return nil after Shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after Shutdown")
+	}
+}
+
+func TestStartContext(t *testing.T) {
+	var hookRan bool
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	srv.OnShutdown(func(ctx context.Context) { hookRan = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.StartContext(ctx, 5*time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartContext did not return after cancellation")
+	}
+	if !hookRan {
+		t.Error("expected OnShutdown hook to run")
+	}
+}
+
+func TestOnExitCleanShutdown(t *testing.T) {
+	var order []string
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	srv.OnShutdown(func(ctx context.Context) { order = append(order, "shutdown") })
+	srv.OnExit(func() { order = append(order, "exit 1") })
+	srv.OnExit(func() { order = append(order, "exit 2") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.StartContext(ctx, 5*time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if want := []string{"shutdown", "exit 1", "exit 2"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected hooks to run in order %v, got %v", want, order)
+	}
+}
+
+func TestOnExitForcedShutdown(t *testing.T) {
+	exited := false
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	srv.OnExit(func() { exited = true })
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	srv.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.run(ctx, 100*time.Millisecond, ready)
+	}()
+	<-ready
+	go http.Get("http://" + srv.Addr() + "/slow")
+	<-started
+	cancel()
+
+	err := <-done
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected shutdown deadline error, got %v", err)
+	}
+	if !exited {
+		t.Error("expected OnExit hook to run after a forced shutdown")
+	}
+}
+
+func TestObserveShutdown(t *testing.T) {
+	var phases []ShutdownPhase
+	var last time.Duration
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	srv.OnShutdown(func(ctx context.Context) { phases = append(phases, -1) })
+	srv.ObserveShutdown(func(phase ShutdownPhase, elapsed time.Duration) {
+		if elapsed < last {
+			t.Errorf("expected elapsed time not to decrease, got %v after %v", elapsed, last)
+		}
+		last = elapsed
+		phases = append(phases, phase)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.run(ctx, 5*time.Second, ready)
+	}()
+	<-ready
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	want := []ShutdownPhase{ShutdownSignaled, ShutdownDraining, ShutdownHooks, -1, ShutdownComplete}
+	if !reflect.DeepEqual(phases, want) {
+		t.Errorf("expected phases %v around the OnShutdown hook, got %v", want, phases)
+	}
+}
+
+func TestListenRetriesWhileAddressInUse(t *testing.T) {
+	held, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := held.Addr().String()
+	time.AfterFunc(100*time.Millisecond, func() { held.Close() })
+
+	srv := New(Config{Addr: addr, ListenRetries: 20, ListenRetryDelay: 20 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.run(ctx, time.Second, ready)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("expected server to bind once the port was freed, got %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for server to bind")
+	}
+	if srv.Addr() != addr {
+		t.Errorf("expected bound address %s, got %s", addr, srv.Addr())
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestListenRetriesGiveUp(t *testing.T) {
+	held, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer held.Close()
+
+	srv := New(Config{Addr: held.Addr().String(), ListenRetries: 2, ListenRetryDelay: 10 * time.Millisecond})
+	err = srv.StartContext(context.Background(), time.Second)
+	if !errors.Is(err, syscall.EADDRINUSE) {
+		t.Errorf("expected address in use error after retries, got %v", err)
+	}
+}
+
+func TestListenRetriesStopOnShutdown(t *testing.T) {
+	held, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer held.Close()
+
+	srv := New(Config{Addr: held.Addr().String(), ListenRetries: 100, ListenRetryDelay: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := srv.StartContext(ctx, time.Second); err != nil {
+		t.Errorf("expected a clean stop while retrying, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("expected cancellation to stop the retries, took %v", elapsed)
+	}
+}
+
+func TestListenDoesNotRetryPermanentErrors(t *testing.T) {
+	srv := New(Config{Addr: "127.0.0.1:notaport", ListenRetries: 5, ListenRetryDelay: time.Second})
+
+	start := time.Now()
+	if err := srv.StartContext(context.Background(), time.Second); err == nil {
+		t.Fatal("expected listen error for an invalid address")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("expected permanent error to fail without retrying, took %v", elapsed)
+	}
+}
+
+func TestHandleWithTimeout(t *testing.T) {
+	work := func(d time.Duration) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(d):
+				fmt.Fprint(w, "done")
+			case <-r.Context().Done():
+			}
+		})
+	}
+
+	srv := New(Config{Addr: ":0"})
+	srv.HandleWithTimeout("/health", work(100*time.Millisecond), 20*time.Millisecond)
+	srv.HandleWithTimeout("/export", work(100*time.Millisecond), 2*time.Second)
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected /health to time out with 503, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/export", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Errorf("expected /export to finish within its own timeout, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A tighter global timeout wins over a looser per-route one
+	srv = New(Config{Addr: ":0"})
+	srv.Use(TimeoutMiddleware(20 * time.Millisecond))
+	srv.HandleWithTimeout("/export", work(100*time.Millisecond), 2*time.Second)
+
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/export", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the global timeout to apply, got %d", w.Code)
+	}
+}
+
+func TestLoggingMiddlewareUsesRouteTemplate(t *testing.T) {
+	mockLog := &mockLogger{}
+	srv := New(Config{Addr: ":0"})
+	srv.Use(LoggingMiddleware(mockLog))
+	srv.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+
+	if len(mockLog.messages) != 1 {
+		t.Fatalf("expected 1 log message, got %d", len(mockLog.messages))
+	}
+	if msg := mockLog.messages[0]; !strings.Contains(msg, "GET /users/{id}") || strings.Contains(msg, "/users/42") {
+		t.Errorf("expected route template in log, got %q", msg)
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", "/raw/path"},
+		{"/users/{id}", "/users/{id}"},
+		{"GET /users/{id}", "/users/{id}"},
+		{"GET example.com/users/{id}", "/users/{id}"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/raw/path", nil)
+		req.Pattern = tt.pattern
+		if got := RouteTemplate(req); got != tt.want {
+			t.Errorf("pattern %q: expected %s, got %s", tt.pattern, tt.want, got)
+		}
+	}
+}
+
+func TestStartWithReady(t *testing.T) {
+	srv := New(Config{Addr: "127.0.0.1:0"})
+	srv.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "pong")
+	})
+
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.StartWithReady(5*time.Second, ready)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("server exited before becoming ready: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not become ready")
+	}
+
+	resp, err := http.Get("http://" + srv.Addr() + "/ping")
+	if err != nil {
+		t.Fatalf("request after ready failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("expected body 'pong', got %q", body)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected StartWithReady to return nil after Shutdown, got %v", err)
+	}
+}
+
+func TestStartWithReadyBindFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	srv := New(Config{Addr: ln.Addr().String()})
+	ready := make(chan struct{})
+	if err := srv.StartWithReady(time.Second, ready); err == nil {
+		t.Error("expected error when the address is in use")
+	}
+	select {
+	case <-ready:
+		t.Error("expected ready not to be closed when binding fails")
+	default:
+	}
+}
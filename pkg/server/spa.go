@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// SPAOptions configures SPA.
+type SPAOptions struct {
+	// APIPrefix is a URL path prefix, such as "/app/api/", whose unmatched
+	// paths respond with 404 Not Found instead of index.html, so API clients
+	// never receive the app's HTML.
+	APIPrefix string
+}
+
+// SPA serves a single-page app from dir under urlPrefix. GET and HEAD
+// requests for files in dir are served as-is; any other path under urlPrefix
+// is answered with dir/index.html and status 200 so client-side routing works:
+//
+//	srv.SPA("/app", "./web/dist", server.SPAOptions{APIPrefix: "/app/api/"})
+//
+// Paths are resolved with http.Dir, so requests cannot escape dir. Routes
+// registered under urlPrefix, such as API handlers, take precedence as usual.
+func (s *Server) SPA(urlPrefix, dir string, opts ...SPAOptions) {
+	var o SPAOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	prefix := strings.TrimSuffix(urlPrefix, "/")
+	fsys := http.Dir(dir)
+
+	s.Handle("GET "+prefix+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, prefix))
+		if serveFile(w, r, fsys, name) {
+			return
+		}
+		if o.APIPrefix != "" && strings.HasPrefix(r.URL.Path, o.APIPrefix) {
+			http.NotFound(w, r)
+			return
+		}
+		if !serveFile(w, r, fsys, "/index.html") {
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// serveFile serves the regular file name from fsys, reporting whether it exists.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys http.FileSystem, name string) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	return true
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSPA(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dist")
+	files := map[string]string{
+		"dist/index.html":    "<html>app</html>",
+		"dist/logo.png":      "PNGDATA",
+		"dist/assets/app.js": "console.log('app')",
+		"secret.txt":         "top secret",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(Config{Addr: ":0"})
+	srv.SPA("/app", dir, SPAOptions{APIPrefix: "/app/api/"})
+	srv.HandleFunc("GET /app/api/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/app/some/route", http.StatusOK, "<html>app</html>"},
+		{"/app/", http.StatusOK, "<html>app</html>"},
+		{"/app/logo.png", http.StatusOK, "PNGDATA"},
+		{"/app/assets/app.js", http.StatusOK, "console.log('app')"},
+		{"/app/assets", http.StatusOK, "<html>app</html>"},
+		{"/app/api/users", http.StatusOK, "users"},
+		{"/app/api/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+
+	if ct := serveSPA(srv, "/app/logo.png").Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("expected image/png for logo.png, got %q", ct)
+	}
+}
+
+func TestSPATraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dist")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644)
+	os.WriteFile(filepath.Join(root, "secret.txt"), []byte("top secret"), 0644)
+
+	srv := New(Config{Addr: ":0"})
+	srv.SPA("/app", dir)
+
+	for _, path := range []string{"/app/../secret.txt", "/app/%2e%2e/secret.txt", "/app/..%2fsecret.txt"} {
+		w := serveSPA(srv, path)
+		if strings.Contains(w.Body.String(), "top secret") {
+			t.Errorf("%s: expected traversal to be blocked, got %q", path, w.Body.String())
+		}
+	}
+
+	// The handler itself never resolves paths outside dir
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/app/x", nil)
+	req.URL.Path = "/app/../secret.txt"
+	srv.routes[0].handler.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "top secret") {
+		t.Errorf("expected handler to stay inside dir, got %q", w.Body.String())
+	}
+}
+
+func serveSPA(srv *Server, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// specContentTypes maps spec file extensions to their content types.
+var specContentTypes = map[string]string{
+	".json": "application/json",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+}
+
+// ServeSpec serves an API specification file, such as an OpenAPI document,
+// at path for GET and HEAD requests:
+//
+//	srv.ServeSpec("/openapi.yaml", "./api/openapi.yaml")
+//
+// The content type is application/json or application/yaml based on the
+// file's extension. Responses carry an ETag derived from the file's contents
+// and Cache-Control: no-cache, so clients revalidate and receive 304 Not
+// Modified while the spec is unchanged. The file is read on each request, so
+// edits are served without a restart; a missing file responds with 404.
+// Middleware added with Use applies as for any other route.
+func (s *Server) ServeSpec(path, file string) {
+	contentType := specContentTypes[strings.ToLower(filepath.Ext(file))]
+
+	s.Handle("GET "+path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(data)
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		// ServeContent answers If-None-Match against the ETag set above
+		http.ServeContent(w, r, filepath.Base(file), info.ModTime(), bytes.NewReader(data))
+	}))
+}
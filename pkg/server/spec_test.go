@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServeSpec(t *testing.T) {
+	dir := t.TempDir()
+	spec := "openapi: 3.1.0\ninfo:\n  title: Orders\n"
+	if err := os.WriteFile(filepath.Join(dir, "openapi.yaml"), []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openapi.json"), []byte(`{"openapi":"3.1.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(Config{Addr: ":0"})
+	srv.ServeSpec("/openapi.yaml", filepath.Join(dir, "openapi.yaml"))
+	srv.ServeSpec("/openapi.json", filepath.Join(dir, "openapi.json"))
+	srv.ServeSpec("/missing.yaml", filepath.Join(dir, "missing.yaml"))
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/openapi.yaml", "")
+	if w.Code != http.StatusOK || w.Body.String() != spec {
+		t.Fatalf("expected 200 with the spec, got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("expected application/yaml, got %q", ct)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	if w := get("/openapi.yaml", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected 304 with no body for a matching If-None-Match, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("/openapi.yaml", `"stale"`); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a stale If-None-Match, got %d", w.Code)
+	}
+	if w := get("/openapi.json", ""); w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected application/json, got %q", w.Header().Get("Content-Type"))
+	}
+	if w := get("/missing.yaml", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing spec file, got %d", w.Code)
+	}
+}
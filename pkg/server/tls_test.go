@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate with the given common name.
+func writeTestCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+// peerCommonName performs a TLS handshake and returns the server certificate's CN.
+func peerCommonName(t *testing.T, addr string) string {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("TLS handshake failed: %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestReloadCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeTestCert(t, certFile, keyFile, "first")
+
+	srv := New(Config{Addr: ":0", CertFile: certFile, KeyFile: keyFile})
+	if err := srv.ReloadCertificates(); err != nil {
+		t.Fatalf("failed to load initial certificate: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go srv.httpServer.ServeTLS(ln, "", "")
+	defer srv.Shutdown(context.Background())
+
+	if cn := peerCommonName(t, ln.Addr().String()); cn != "first" {
+		t.Errorf("expected initial certificate 'first', got %q", cn)
+	}
+
+	writeTestCert(t, certFile, keyFile, "second")
+	if err := srv.ReloadCertificates(); err != nil {
+		t.Fatalf("failed to reload certificate: %v", err)
+	}
+
+	if cn := peerCommonName(t, ln.Addr().String()); cn != "second" {
+		t.Errorf("expected reloaded certificate 'second', got %q", cn)
+	}
+}
+
+func TestReloadCertificatesWithoutTLS(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	if err := srv.ReloadCertificates(); err == nil {
+		t.Error("expected error when TLS is not configured")
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+const (
+	// ServerStartHeader carries the process start time as Unix seconds.
+	ServerStartHeader = "X-Server-Start"
+	// ServerInstanceHeader carries the instance identifier.
+	ServerInstanceHeader = "X-Server-Instance"
+)
+
+// processStart is when the process started serving code from this package.
+var processStart = time.Now()
+
+// VersionInfo describes the build of the running application.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// VersionHandler returns an http.Handler that renders info as JSON.
+// Empty fields are filled from runtime/debug.ReadBuildInfo when available,
+// using the main module version and the VCS revision and time recorded by go build.
+func VersionHandler(info VersionInfo) http.Handler {
+	info = info.withBuildInfo()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	})
+}
+
+// withBuildInfo fills empty fields from the binary's embedded build information.
+func (v VersionInfo) withBuildInfo() VersionInfo {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	if v.Version == "" {
+		v.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if v.Commit == "" {
+				v.Commit = setting.Value
+			}
+		case "vcs.time":
+			if v.BuildTime == "" {
+				v.BuildTime = setting.Value
+			}
+		}
+	}
+	return v
+}
+
+// ServerInfoOptions configures ServerInfoMiddleware.
+type ServerInfoOptions struct {
+	// Instance is sent in an X-Server-Instance header, for example a pod name.
+	Instance string
+	// Hostname sends the host name as the instance when Instance is empty.
+	Hostname bool
+}
+
+// ServerInfoMiddleware tags every response with an X-Server-Start header
+// holding the process start time in Unix seconds, and optionally an
+// X-Server-Instance header, so client reports during a canary rollout can be
+// traced to the instance that served them. The start time is the same for
+// every request until the process restarts.
+func ServerInfoMiddleware(opts ...ServerInfoOptions) Middleware {
+	var o ServerInfoOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	instance := o.Instance
+	if instance == "" && o.Hostname {
+		instance, _ = os.Hostname()
+	}
+	start := strconv.FormatInt(processStart.Unix(), 10)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(ServerStartHeader, start)
+			if instance != "" {
+				w.Header().Set(ServerInstanceHeader, instance)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVersionHandler(t *testing.T) {
+	handler := VersionHandler(VersionInfo{
+		Version:   "1.2.3",
+		Commit:    "abc123",
+		BuildTime: "2025-01-01T00:00:00Z",
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %s", ct)
+	}
+
+	var info VersionInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode version JSON: %v", err)
+	}
+	if info.Version != "1.2.3" {
+		t.Errorf("expected version 1.2.3, got %s", info.Version)
+	}
+	if info.Commit != "abc123" {
+		t.Errorf("expected commit abc123, got %s", info.Commit)
+	}
+	if info.BuildTime != "2025-01-01T00:00:00Z" {
+		t.Errorf("expected build time to be preserved, got %s", info.BuildTime)
+	}
+}
+
+func TestVersionHandlerFallback(t *testing.T) {
+	w := httptest.NewRecorder()
+	VersionHandler(VersionInfo{}).ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	var info VersionInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode version JSON: %v", err)
+	}
+	// Test binaries always embed a main module version
+	if info.Version == "" {
+		t.Error("expected version to fall back to build info")
+	}
+}
+
+func TestServerInfoMiddleware(t *testing.T) {
+	srv := New(Config{Addr: ":0"})
+	srv.Use(ServerInfoMiddleware(ServerInfoOptions{Instance: "pod-7"}))
+	srv.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	first := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(first, httptest.NewRequest("GET", "/", nil))
+	second := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(second, httptest.NewRequest("GET", "/", nil))
+
+	start := first.Header().Get(ServerStartHeader)
+	epoch, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || epoch <= 0 || epoch > time.Now().Unix() {
+		t.Errorf("expected a Unix start time, got %q", start)
+	}
+	if want := strconv.FormatInt(processStart.Unix(), 10); start != want {
+		t.Errorf("expected the process start time %s, got %q", want, start)
+	}
+	if got := second.Header().Get(ServerStartHeader); got != start {
+		t.Errorf("expected a stable start time, got %q then %q", start, got)
+	}
+	if got := first.Header().Get(ServerInstanceHeader); got != "pod-7" {
+		t.Errorf("expected instance pod-7, got %q", got)
+	}
+}
+
+func TestServerInfoMiddlewareHostname(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	ServerInfoMiddleware(ServerInfoOptions{Hostname: true})(http.NotFoundHandler()).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get(ServerInstanceHeader); got != hostname {
+		t.Errorf("expected instance %q, got %q", hostname, got)
+	}
+
+	w = httptest.NewRecorder()
+	ServerInfoMiddleware()(http.NotFoundHandler()).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get(ServerInstanceHeader); got != "" {
+		t.Errorf("expected no instance header by default, got %q", got)
+	}
+}